@@ -0,0 +1,79 @@
+package bumpversion
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pulumi/pulumictl/cmd/pulumictl/get/version"
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
+)
+
+func Command(registry *config.Registry) *cobra.Command {
+	viper := viperlib.New()
+	command := &cobra.Command{
+		Use:   "bump-version",
+		Short: "Calculate the next release or prerelease version",
+		Long: "Print the version after the most recent tag, incremented by --major, --minor or --patch, or the " +
+			"next prerelease with --pre, e.g. 3.2.0-rc.2 after 3.2.0-rc.1. Prerelease tags are considered " +
+			"as the base version. With --tag, the version is also tagged at HEAD, using the module prefix " +
+			"of the base tag.",
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			increment := gitversion.IncrementNone
+			for _, level := range []gitversion.Increment{
+				gitversion.IncrementMajor, gitversion.IncrementMinor, gitversion.IncrementPatch,
+			} {
+				if !viper.GetBool(string(level)) {
+					continue
+				}
+				if increment != gitversion.IncrementNone {
+					return fmt.Errorf("only one of --major, --minor and --patch can be given")
+				}
+				increment = level
+			}
+
+			opts, err := version.Options(viper, "HEAD")
+			if err != nil {
+				return err
+			}
+
+			next, err := gitversion.NextVersion(opts, increment, viper.GetString("pre"))
+			if err != nil {
+				return fmt.Errorf("error calculating next version: %w", err)
+			}
+
+			if viper.GetBool("tag") {
+				if _, err := opts.Repo.CreateTag(next.Tag, next.Commit, nil); err != nil {
+					return fmt.Errorf("error creating tag %s: %w", next.Tag, err)
+				}
+				fmt.Fprintf(os.Stderr, "created tag %s at %s\n", next.Tag, next.Commit.String()[:8])
+			}
+
+			fmt.Println(next.Version)
+			return nil
+		},
+	}
+
+	command.Flags().Bool("major", false, "increment the major version")
+	command.Flags().Bool("minor", false, "increment the minor version")
+	command.Flags().Bool("patch", false, "increment the patch version")
+	command.Flags().String("pre", "", "make the next version a prerelease with this identifier (alpha, beta or rc)")
+	command.Flags().Bool("tag", false, "create a lightweight tag for the next version at HEAD")
+	version.AddOptionFlags(command, viper)
+
+	util.NoErr(viper.BindPFlag("major", command.Flags().Lookup("major")))
+	util.NoErr(viper.BindPFlag("minor", command.Flags().Lookup("minor")))
+	util.NoErr(viper.BindPFlag("patch", command.Flags().Lookup("patch")))
+	util.NoErr(viper.BindPFlag("pre", command.Flags().Lookup("pre")))
+	util.NoErr(viper.BindPFlag("tag", command.Flags().Lookup("tag")))
+
+	// The base version is found from the settings for `get version`, so that both commands agree
+	registry.Register("bump-version", viper, "get.version")
+
+	return command
+}
@@ -0,0 +1,56 @@
+package compareversion
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
+)
+
+// Exit statuses for the result of a comparison. Errors exit with 1, as for every other command.
+const (
+	exitLess    = 2
+	exitGreater = 3
+)
+
+func Command(registry *config.Registry) *cobra.Command {
+	viper := viperlib.New()
+	command := &cobra.Command{
+		Use:   "compare-version <a> <b>",
+		Short: "Compare versions",
+		Long: "Compare two versions the way the package registry for a language orders them: semver precedence " +
+			"for generic and javascript (npm), PEP440 for python (PyPI) and NuGet rules for dotnet. Prints -1, 0 " +
+			"or 1 if <a> sorts before, the same as or after <b>, and exits with 2, 0 or 3 respectively.",
+		Args: cobra.ExactArgs(2),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := gitversion.CompareVersions(viper.GetString("language"), args[0], args[1])
+			if err != nil {
+				return fmt.Errorf("error comparing versions: %w", err)
+			}
+
+			fmt.Println(result)
+			switch result {
+			case -1:
+				os.Exit(exitLess)
+			case 1:
+				os.Exit(exitGreater)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringP("language", "l", "generic",
+		"the platform whose registry ordering to use (generic, python, javascript or dotnet).")
+
+	util.NoErr(viper.BindEnv("language", "PULUMI_LANGUAGE"))
+	util.NoErr(viper.BindPFlag("language", command.Flags().Lookup("language")))
+
+	registry.Register("compare-version", viper)
+
+	return command
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	cfg "github.com/pulumi/pulumictl/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func Command(registry *cfg.Registry) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Config commands",
+		Long:  "Commands for the " + cfg.FileName + " file at the root of the repository",
+	}
+
+	command.AddCommand(showCommand(registry))
+
+	return command
+}
+
+func showCommand(registry *cfg.Registry) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [command...]",
+		Short: "Show the effective settings",
+		Long: "Show the settings each command would use after merging flags, environment variables, " +
+			cfg.FileName + " and defaults, optionally for a single command such as `get version`",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespaces := registry.Namespaces()
+			if len(args) > 0 {
+				namespaces = []string{strings.Join(args, ".")}
+			}
+
+			// Nest the settings by namespace, as in the config file
+			settings := map[string]interface{}{}
+			for _, namespace := range namespaces {
+				values, err := registry.Settings(namespace)
+				if err != nil {
+					return err
+				}
+
+				parts := strings.Split(namespace, ".")
+				parent := settings
+				for _, part := range parts[:len(parts)-1] {
+					child, ok := parent[part].(map[string]interface{})
+					if !ok {
+						child = map[string]interface{}{}
+						parent[part] = child
+					}
+					parent = child
+				}
+				parent[parts[len(parts)-1]] = values
+			}
+
+			if file := registry.File(); file != "" {
+				fmt.Printf("# loaded from %s\n", file)
+			} else {
+				fmt.Printf("# no %s found\n", cfg.FileName)
+			}
+
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(settings); err != nil {
+				return err
+			}
+			return encoder.Close()
+		},
+	}
+}
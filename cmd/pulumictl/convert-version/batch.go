@@ -0,0 +1,88 @@
+package convertVersion //nolint:revive // backwards compatibility
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	getversion "github.com/pulumi/pulumictl/cmd/pulumictl/get/version"
+)
+
+// batchRow is a row of --batch output. Rows for lines which failed to convert only have the input
+// and the error, and rows for blank lines only the empty input, so that rows line up with lines.
+type batchRow struct {
+	Input string `json:"input"`
+	*getversion.LanguageVersionsJSON
+	Error string `json:"error,omitempty"`
+}
+
+// convertBatchFile converts each line of `inputFile`, or stdin if it is empty, with convertBatch.
+func convertBatchFile(inputFile, output, from string, strict bool) error {
+	var input io.Reader = os.Stdin
+	if inputFile != "" {
+		f, err := os.Open(inputFile)
+		if err != nil {
+			return fmt.Errorf("opening input file: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	return convertBatch(input, os.Stdout, os.Stderr, output, from, strict)
+}
+
+// convertBatch converts each line of `input` and writes a row of versions for each to `stdout`.
+// Lines which fail to convert are reported on `stderr`, and make the batch fail once every line has
+// been converted.
+func convertBatch(input io.Reader, stdout, stderr io.Writer, output, from string, strict bool) error {
+	var write func(row batchRow) error
+	switch strings.ToLower(output) {
+	case "", "tsv":
+		write = func(row batchRow) error {
+			v := row.LanguageVersionsJSON
+			if v == nil {
+				v = &getversion.LanguageVersionsJSON{}
+			}
+			_, err := fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				row.Input, v.SemVer, v.Python, v.JavaScript, v.DotNet, v.Java, row.Error)
+			return err
+		}
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		write = func(row batchRow) error {
+			return encoder.Encode(row)
+		}
+	default:
+		return fmt.Errorf("invalid output format %q", output)
+	}
+
+	var lines, failed int
+	scanner := bufio.NewScanner(input)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		row := batchRow{Input: strings.TrimSpace(scanner.Text())}
+		if row.Input != "" {
+			lines++
+			versions, err := convert(row.Input, from, strict)
+			if err != nil {
+				failed++
+				row.Error = err.Error()
+				fmt.Fprintf(stderr, "line %d: %s\n", lineNumber, err)
+			} else {
+				row.LanguageVersionsJSON = getversion.NewLanguageVersionsJSON(versions)
+			}
+		}
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading versions: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d versions failed to convert", failed, lines)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package convertVersion //nolint:revive // backwards compatibility
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertBatch(t *testing.T) {
+	tests := []struct {
+		desc   string
+		input  string
+		output string
+		from   string
+		stdout string
+		stderr string
+		err    string
+	}{
+		{
+			desc:  "good lines",
+			input: "1.2.3\n1.0.0-alpha.1\n",
+			stdout: "1.2.3\t1.2.3\t1.2.3\tv1.2.3\t1.2.3\t1.2.3\t\n" +
+				"1.0.0-alpha.1\t1.0.0-alpha.1\t1.0.0a1\tv1.0.0-alpha.1\t1.0.0-alpha.1\t1.0.0-alpha.1\t\n",
+		},
+		{
+			desc:  "blank lines keep rows lined up with lines",
+			input: "1.2.3\n\n  \n1.2.4",
+			stdout: "1.2.3\t1.2.3\t1.2.3\tv1.2.3\t1.2.3\t1.2.3\t\n" +
+				"\t\t\t\t\t\t\n" +
+				"\t\t\t\t\t\t\n" +
+				"1.2.4\t1.2.4\t1.2.4\tv1.2.4\t1.2.4\t1.2.4\t\n",
+		},
+		{
+			desc:  "bad lines are reported and the batch keeps going",
+			input: "1.2.3\n1.0.0-alpha.1.2.3\n\n2.0.0\n",
+			stdout: "1.2.3\t1.2.3\t1.2.3\tv1.2.3\t1.2.3\t1.2.3\t\n" +
+				"1.0.0-alpha.1.2.3\t\t\t\t\t\terror calculating version: converting \"1.0.0-alpha.1.2.3\" to a Python " +
+				"version: prerelease \"alpha.1.2.3\" has more numbers than PEP440 allows\n" +
+				"\t\t\t\t\t\t\n" +
+				"2.0.0\t2.0.0\t2.0.0\tv2.0.0\t2.0.0\t2.0.0\t\n",
+			stderr: "line 2: error calculating version: converting \"1.0.0-alpha.1.2.3\" to a Python version: " +
+				"prerelease \"alpha.1.2.3\" has more numbers than PEP440 allows\n",
+			err: "1 of 3 versions failed to convert",
+		},
+		{
+			desc:   "json",
+			input:  "1.0.0a1\n\n1.0.0.post1\n",
+			output: "json",
+			from:   "python",
+			stdout: `{"input":"1.0.0a1","semver":"1.0.0-alpha.1","python":"1.0.0a1","javascript":"v1.0.0-alpha.1",` +
+				`"dotnet":"1.0.0-alpha.1","java":"1.0.0-alpha.1"}` + "\n" +
+				`{"input":""}` + "\n" +
+				`{"input":"1.0.0.post1","error":"error converting from python: converting \"1.0.0.post1\": ` +
+				`post-releases have no semver equivalent"}` + "\n",
+			stderr: "line 3: error converting from python: converting \"1.0.0.post1\": post-releases have no semver " +
+				"equivalent\n",
+			err: "1 of 2 versions failed to convert",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := convertBatch(strings.NewReader(test.input), &stdout, &stderr, test.output, test.from, true)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.err)
+			}
+			require.Equal(t, test.stdout, stdout.String())
+			require.Equal(t, test.stderr, stderr.String())
+		})
+	}
+
+	err := convertBatch(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, "yaml", "", false)
+	require.EqualError(t, err, `invalid output format "yaml"`)
+}
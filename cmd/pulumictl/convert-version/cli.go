@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pulumi/pulumictl/pkg/config"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
 	"github.com/spf13/cobra"
@@ -13,16 +14,20 @@ import (
 var (
 	language string
 	version  string
+	from     string
+	strict   bool
+	batch    bool
 )
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 
 	command := &cobra.Command{
 		Use:   "convert-version",
 		Short: "Convert versions",
-		Long:  "Convert a generic version into a language specific version",
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Convert a generic version into a language specific version, or with --from, a language " +
+			"specific version into any other language",
+		Args: cobra.MaximumNArgs(1),
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cmd.ParseFlags(args); err != nil {
@@ -31,11 +36,21 @@ func Command() *cobra.Command {
 
 			language = viper.GetString("language")
 			version = viper.GetString("version")
+			from = viper.GetString("from")
+			strict = viper.GetBool("strict")
+			batch = viper.GetBool("batch")
 
-			versions, err := gitversion.GetLanguageOptionsFromVersion(version)
+			if batch {
+				return convertBatchFile(viper.GetString("input-file"), viper.GetString("output"), from, strict)
+			}
+
+			if from != "" && language == "" {
+				language = "generic"
+			}
 
+			versions, err := convert(version, from, strict)
 			if err != nil {
-				return fmt.Errorf("error calculating version: %w", err)
+				return err
 			}
 
 			// FIXME: We could get the values here from the struct fields?
@@ -48,6 +63,8 @@ func Command() *cobra.Command {
 				fmt.Println(versions.JavaScript)
 			case "dotnet":
 				fmt.Println(versions.DotNet)
+			case "java":
+				fmt.Println(versions.Java)
 			default:
 				return fmt.Errorf("invalid language %q ", language)
 			}
@@ -62,6 +79,19 @@ func Command() *cobra.Command {
 	command.Flags().StringVarP(&version,
 		"version", "v", "",
 		"the generic version to convert (e.g. 3.0.0). Must be valid semver.")
+	command.Flags().StringVar(&from,
+		"from", "",
+		"the platform the version is from, to convert a language specific version (python, dotnet or javascript).")
+	command.Flags().BoolVar(&strict,
+		"strict", false,
+		"fail on versions which can only be converted by guessing, e.g. unknown prerelease identifiers.")
+	command.Flags().BoolVar(&batch,
+		"batch", false,
+		"convert one version per line from stdin or --input-file, writing every language for each.")
+	command.Flags().String("input-file", "", "the file to read versions from with --batch, instead of stdin.")
+	command.Flags().String("output", "tsv",
+		"the format of --batch rows (tsv or json), one for each input line. TSV columns are the input, generic, "+
+			"python, javascript, dotnet and java versions, and the error for lines which failed to convert.")
 
 	util.NoErr(viper.BindEnv("language", "PULUMI_LANGUAGE"))
 	util.NoErr(viper.BindPFlag("language", command.Flags().Lookup("language")))
@@ -69,5 +99,33 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindEnv("version", "VERSION"))
 	util.NoErr(viper.BindPFlag("version", command.Flags().Lookup("version")))
 
+	util.NoErr(viper.BindPFlag("from", command.Flags().Lookup("from")))
+
+	util.NoErr(viper.BindPFlag("strict", command.Flags().Lookup("strict")))
+
+	util.NoErr(viper.BindPFlag("batch", command.Flags().Lookup("batch")))
+	util.NoErr(viper.BindPFlag("input-file", command.Flags().Lookup("input-file")))
+	util.NoErr(viper.BindPFlag("output", command.Flags().Lookup("output")))
+
+	registry.Register("convert-version", viper)
+
 	return command
 }
+
+// convert converts `version`, which is for the `from` language or generic if it is empty, into the
+// version for each language.
+func convert(version, from string, strict bool) (*gitversion.LanguageVersions, error) {
+	if from != "" {
+		generic, err := gitversion.GetVersionFromLanguageVersion(from, version)
+		if err != nil {
+			return nil, fmt.Errorf("error converting from %s: %w", from, err)
+		}
+		version = generic
+	}
+
+	versions, err := gitversion.ConvertVersion(version, strict)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating version: %w", err)
+	}
+	return versions, nil
+}
@@ -12,10 +12,14 @@ import (
 	"time"
 
 	dstar "github.com/bmatcuk/doublestar"
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/util"
 	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
 )
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
+	viper := viperlib.New()
 
 	command := &cobra.Command{
 		Use:   "copyright",
@@ -28,32 +32,12 @@ func Command() *cobra.Command {
 				return err
 			}
 
-			repo, err := cmd.Flags().GetString("repo")
-			if err != nil {
-				return err
-			}
+			repo := viper.GetString("repo")
+			fixup := viper.GetBool("fixup")
+			lines := viper.GetInt("lines")
+			parallelism := viper.GetInt("parallelism")
 
-			fixup, err := cmd.Flags().GetBool("fixup")
-			if err != nil {
-				return err
-			}
-
-			lines, err := cmd.Flags().GetInt("lines")
-			if err != nil {
-				return err
-			}
-
-			exclude, err := cmd.Flags().GetString("exclude")
-			if err != nil {
-				return err
-			}
-
-			parallelism, err := cmd.Flags().GetInt("parallelism")
-			if err != nil {
-				return err
-			}
-
-			c := newChecker(repo, strings.Split(exclude, ","), lines, parallelism)
+			c := newChecker(repo, excludePatterns(viper.Get("exclude")), lines, parallelism)
 
 			if fixup {
 				return c.executeFixup()
@@ -68,9 +52,35 @@ func Command() *cobra.Command {
 	command.Flags().Int("lines", 20, "max head lines to scan in each file")
 	command.Flags().StringP("exclude", "x", "", "patterns to exclude from copyright checks (',' separated)")
 
+	util.NoErr(viper.BindPFlag("repo", command.Flags().Lookup("repo")))
+	config.MarkRepoFlag(command.Flags(), "repo")
+	util.NoErr(viper.BindPFlag("fixup", command.Flags().Lookup("fixup")))
+	util.NoErr(viper.BindPFlag("parallelism", command.Flags().Lookup("parallelism")))
+	util.NoErr(viper.BindPFlag("lines", command.Flags().Lookup("lines")))
+	util.NoErr(viper.BindPFlag("exclude", command.Flags().Lookup("exclude")))
+
+	registry.Register("copyright", viper)
+
 	return command
 }
 
+// excludePatterns returns the exclusions given either as a ',' separated string, as with the
+// --exclude flag, or as a list in the config file.
+func excludePatterns(value interface{}) []string {
+	switch value := value.(type) {
+	case []interface{}:
+		patterns := make([]string, 0, len(value))
+		for _, pattern := range value {
+			patterns = append(patterns, fmt.Sprint(pattern))
+		}
+		return patterns
+	case []string:
+		return value
+	default:
+		return strings.Split(fmt.Sprint(value), ",")
+	}
+}
+
 type checker struct {
 	repo                 string
 	exclude              []string
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	viperlib "github.com/spf13/viper"
 
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -28,7 +29,7 @@ type Payload struct {
 
 const eventType = "oss-sdk"
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 	command := &cobra.Command{
 		Use:   "oss-sdk [gitRef]",
@@ -98,5 +99,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindEnv("org", "GITHUB_ORG"))
 	util.NoErr(viper.BindPFlag("org", command.Flags().Lookup("org")))
 
+	registry.Register("create.oss-sdk", viper)
+
 	return command
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/google/go-github/v32/github"
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -29,7 +30,7 @@ type Payload struct {
 
 const eventType = "choco-deploy"
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 	command := &cobra.Command{
 		Use:   "choco-deploy [tag]",
@@ -112,5 +113,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindPFlag("org", command.Flags().Lookup("org")))
 	util.NoErr(viper.BindPFlag("app", command.Flags().Lookup("app")))
 
+	registry.Register("create.choco-deploy", viper)
+
 	return command
 }
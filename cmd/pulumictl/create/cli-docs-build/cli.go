@@ -9,6 +9,7 @@ import (
 	"github.com/blang/semver"
 
 	"github.com/google/go-github/v32/github"
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -26,7 +27,7 @@ type Payload struct {
 	Ref string `json:"ref"`
 }
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 	command := &cobra.Command{
 		Use:   "cli-docs-build [tag]",
@@ -104,5 +105,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindPFlag("docs-repo", command.Flags().Lookup("docs-repo")))
 	util.NoErr(viper.BindPFlag("event-type", command.Flags().Lookup("event-type")))
 
+	registry.Register("create.cli-docs-build", viper)
+
 	return command
 }
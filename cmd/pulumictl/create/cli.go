@@ -6,21 +6,22 @@ import (
 	pulumiCliDocsbuild "github.com/pulumi/pulumictl/cmd/pulumictl/create/cli-docs-build"
 	docsbuild "github.com/pulumi/pulumictl/cmd/pulumictl/create/docs-build"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/create/homebrew"
+	"github.com/pulumi/pulumictl/pkg/config"
 	"github.com/spf13/cobra"
 )
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "create",
 		Short: "Create commands",
 		Long:  "Commands that create resource or objects",
 	}
 
-	command.AddCommand(docsbuild.Command())
-	command.AddCommand(pulumiCliDocsbuild.Command())
-	command.AddCommand(chocolatey.Command())
-	command.AddCommand(homebrew.Command())
-	command.AddCommand(azure.Command())
+	command.AddCommand(docsbuild.Command(registry))
+	command.AddCommand(pulumiCliDocsbuild.Command(registry))
+	command.AddCommand(chocolatey.Command(registry))
+	command.AddCommand(homebrew.Command(registry))
+	command.AddCommand(azure.Command(registry))
 
 	return command
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/blang/semver"
 
 	"github.com/google/go-github/v32/github"
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -42,7 +43,7 @@ type Payload struct {
 
 const eventType = "resource-provider"
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 	command := &cobra.Command{
 		Use:   "docs-build [provider] [tag]",
@@ -146,5 +147,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindPFlag("schema-path", command.Flags().Lookup("schema-path")))
 	util.NoErr(viper.BindPFlag("publisher", command.Flags().Lookup("publisher")))
 
+	registry.Register("create.docs-build", viper)
+
 	return command
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/google/go-github/v32/github"
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -29,7 +30,7 @@ type Payload struct {
 
 const eventType = "homebrew-bump"
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 
 	command := &cobra.Command{
@@ -102,5 +103,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindEnv("org", "GITHUB_ORG"))
 	util.NoErr(viper.BindPFlag("org", command.Flags().Lookup("org")))
 
+	registry.Register("create.homebrew-bump", viper)
+
 	return command
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/blang/semver"
 
 	"github.com/google/go-github/v32/github"
+	"github.com/pulumi/pulumictl/pkg/config"
 	gh "github.com/pulumi/pulumictl/pkg/github"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
@@ -19,7 +20,7 @@ type Payload struct {
 	Ref string `json:"ref"`
 }
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 
 	command := &cobra.Command{
@@ -96,5 +97,7 @@ func Command() *cobra.Command {
 	util.NoErr(viper.BindPFlag("repo", command.Flags().Lookup("repo")))
 	util.NoErr(viper.BindPFlag("command", command.Flags().Lookup("command")))
 
+	registry.Register("dispatch", viper)
+
 	return command
 }
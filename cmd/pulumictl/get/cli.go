@@ -2,19 +2,22 @@ package get
 
 import (
 	"github.com/pulumi/pulumictl/cmd/pulumictl/get/latest_plugin"
+	"github.com/pulumi/pulumictl/pkg/config"
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumictl/cmd/pulumictl/get/version"
+	"github.com/pulumi/pulumictl/cmd/pulumictl/get/versions"
 )
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "get",
 		Short: "Get commands",
 		Long:  "Commands that return information",
 	}
 
-	command.AddCommand(version.Command())
+	command.AddCommand(version.Command(registry))
+	command.AddCommand(versions.Command(registry))
 	command.AddCommand(latest_plugin.Command())
 
 	return command
@@ -1,13 +1,14 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pulumi/pulumictl/pkg/config"
 	"github.com/pulumi/pulumictl/pkg/gitversion"
 	"github.com/pulumi/pulumictl/pkg/util"
 	"github.com/spf13/cobra"
@@ -15,14 +16,14 @@ import (
 )
 
 var (
-	language       string
-	versionPrefix  string
-	omitCommitHash bool
-	isPreRelease   bool
-	tagPattern     string
+	language     string
+	output       string
+	debugDirty   bool
+	githubOutput bool
+	githubEnv    bool
 )
 
-func Command() *cobra.Command {
+func Command(registry *config.Registry) *cobra.Command {
 	viper := viperlib.New()
 	command := &cobra.Command{
 		Use:   "version",
@@ -40,49 +41,49 @@ func Command() *cobra.Command {
 				commitish = args[0]
 			}
 
-			workingDir, _ := cmd.Flags().GetString("repo")
-			if workingDir == "" {
-				var err error
-				workingDir, err = os.Getwd()
-				if err != nil {
-					return fmt.Errorf("error obtaining working directory: %w", err)
-				}
-			}
-
 			language = viper.GetString("language")
-			versionPrefix = viper.GetString("version-prefix")
-			isPreRelease = viper.GetBool("is-prerelease")
-			tagPattern = viper.GetString("tag-pattern")
+			output = viper.GetString("output")
+			debugDirty = viper.GetBool("debug-dirty")
+			githubOutput = viper.GetBool("github-output")
+			githubEnv = viper.GetBool("github-env")
 
-			var tagFilter func(string) bool
-			if tagPattern != "" {
-				re, err := regexp.Compile(tagPattern)
-				if err != nil {
-					return fmt.Errorf("tag-pattern not a valid regexp: %w", err)
-				}
-				tagFilter = func(tag string) bool {
-					return re.MatchString(tag)
-				}
+			opts, err := Options(viper, commitish)
+			if err != nil {
+				return err
 			}
+			opts.GoModuleDir = viper.GetString("go-module-dir")
 
-			repo, err := git.PlainOpenWithOptions(workingDir, &git.PlainOpenOptions{
-				DetectDotGit:          true,
-				EnableDotGitCommonDir: true})
+			versions, err := gitversion.GetVersionDetailsWithOptions(opts)
 			if err != nil {
-				return fmt.Errorf("error opening repository: %w", err)
+				return fmt.Errorf("error calculating version: %w", err)
 			}
 
-			versions, err := gitversion.GetLanguageVersionsWithOptions(gitversion.LanguageVersionsOptions{
-				Repo:           repo,
-				Commitish:      plumbing.Revision(commitish),
-				OmitCommitHash: omitCommitHash,
-				ReleasePrefix:  versionPrefix,
-				IsPreRelease:   isPreRelease,
-				TagFilter:      tagFilter,
-			})
+			// --debug has always printed the dirty files too
+			if debugDirty || viperlib.GetBool("debug") {
+				for _, file := range versions.DirtyFiles {
+					fmt.Fprintf(os.Stderr, "dirty: %s\n", file)
+				}
+			}
 
-			if err != nil {
-				return fmt.Errorf("error calculating version: %w", err)
+			if githubOutput {
+				if err := appendGitHubFile("GITHUB_OUTPUT", versions); err != nil {
+					return err
+				}
+			}
+			if githubEnv {
+				if err := appendGitHubFile("GITHUB_ENV", versions); err != nil {
+					return err
+				}
+			}
+
+			switch strings.ToLower(output) {
+			case "":
+			case "json":
+				return writeJSON(os.Stdout, versions)
+			case "env":
+				return writeEnv(os.Stdout, versions)
+			default:
+				return fmt.Errorf("invalid output format %q", output)
 			}
 
 			// FIXME: We could get the values here from the struct fields?
@@ -95,6 +96,14 @@ func Command() *cobra.Command {
 				fmt.Println(versions.JavaScript)
 			case "dotnet":
 				fmt.Println(versions.DotNet)
+			case "java":
+				fmt.Println(versions.Java)
+			case "go":
+				goVersion, err := gitversion.GetGoVersionWithOptions(opts)
+				if err != nil {
+					return fmt.Errorf("error calculating go version: %w", err)
+				}
+				fmt.Println(goVersion)
 			default:
 				return fmt.Errorf("invalid language %q ", language)
 			}
@@ -103,27 +112,107 @@ func Command() *cobra.Command {
 		},
 	}
 
-	command.Flags().StringP("repo", "r", "", "path to repository, defaults to current working directory")
+	AddOptionFlags(command, viper)
 	command.Flags().StringVarP(&language, "language", "p", "", "the platform for which the version should be output.")
-	command.Flags().StringVar(&versionPrefix,
-		"version-prefix", "", "the version prefix (e.g. 3.0.0). Must be valid semver.")
-	command.Flags().BoolVarP(&omitCommitHash,
-		"omit-commit-hash", "o", false, "whether to include or omit the commit hash in the version")
-	command.Flags().BoolVar(&isPreRelease, "is-prerelease", false, "whether this is a pre-release version")
-	command.Flags().StringVar(&tagPattern, "tag-pattern", "", "regex pattern to filter tags with (e.g. ^sdk/)")
+	command.Flags().String("go-module-dir", "",
+		"the directory containing go.mod, relative to the repository root, for --language go")
+	command.Flags().BoolVar(&debugDirty, "debug-dirty", false,
+		"print the files which made the version dirty to stderr")
+	command.Flags().StringVar(&output, "output", "",
+		"output all versions and metadata at once instead of a single version (json or env)")
+	command.Flags().BoolVar(&githubOutput, "github-output", false,
+		"append all versions and metadata to $GITHUB_OUTPUT, for use as GitHub Actions step outputs")
+	command.Flags().BoolVar(&githubEnv, "github-env", false,
+		"append all versions and metadata to $GITHUB_ENV, for use as environment variables in later steps")
 
 	viper.SetDefault("language", "generic")
 	util.NoErr(viper.BindEnv("language", "PULUMI_LANGUAGE"))
 	util.NoErr(viper.BindPFlag("language", command.Flags().Lookup("language")))
 
-	util.NoErr(viper.BindEnv("version-prefix", "VERSION_PREFIX"))
-	util.NoErr(viper.BindPFlag("version-prefix", command.Flags().Lookup("version-prefix")))
+	util.NoErr(viper.BindPFlag("go-module-dir", command.Flags().Lookup("go-module-dir")))
+
+	util.NoErr(viper.BindPFlag("debug-dirty", command.Flags().Lookup("debug-dirty")))
+
+	util.NoErr(viper.BindPFlag("output", command.Flags().Lookup("output")))
 
-	util.NoErr(viper.BindEnv("is-prerelease", "IS_PRERELEASE"))
-	util.NoErr(viper.BindPFlag("is-prerelease", command.Flags().Lookup("is-prerelease")))
+	util.NoErr(viper.BindPFlag("github-output", command.Flags().Lookup("github-output")))
+	util.NoErr(viper.BindPFlag("github-env", command.Flags().Lookup("github-env")))
 
-	util.NoErr(viper.BindEnv("tag-pattern", "TAG_PATTERN"))
-	util.NoErr(viper.BindPFlag("tag-pattern", command.Flags().Lookup("tag-pattern")))
+	registry.Register("get.version", viper)
 
 	return command
 }
+
+// envVars returns the calculated versions and their metadata as ordered KEY=value pairs.
+func envVars(versions *gitversion.VersionDetails) [][2]string {
+	return [][2]string{
+		{"VERSION", versions.SemVer},
+		{"PYTHON_VERSION", versions.Python},
+		{"JAVASCRIPT_VERSION", versions.JavaScript},
+		{"DOTNET_VERSION", versions.DotNet},
+		{"JAVA_VERSION", versions.Java},
+		{"BASE_TAG", versions.BaseTag},
+		{"IS_EXACT", strconv.FormatBool(versions.IsExact)},
+		{"IS_DIRTY", strconv.FormatBool(versions.Dirty)},
+		{"SHORT_HASH", versions.ShortHash},
+		{"COMMIT_TIMESTAMP", strconv.FormatInt(versions.Timestamp.UTC().Unix(), 10)},
+	}
+}
+
+func writeEnv(w io.Writer, versions *gitversion.VersionDetails) error {
+	for _, kv := range envVars(versions) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendGitHubFile appends the calculated versions to the file named by the environment variable
+// `name`, such as GITHUB_OUTPUT, which GitHub Actions sets for each step.
+func appendGitHubFile(name string, versions *gitversion.VersionDetails) error {
+	path := os.Getenv(name)
+	if path == "" {
+		return fmt.Errorf("$%s is not set, are we running in GitHub Actions?", name)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("opening $%s: %w", name, err)
+	}
+	if err := writeGitHubVars(f, envVars(versions)); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing $%s: %w", name, err)
+	}
+	return f.Close()
+}
+
+// gitHubDelimiter ends multi-line values in GITHUB_OUTPUT and GITHUB_ENV files.
+const gitHubDelimiter = "PULUMICTL_EOF"
+
+// writeGitHubVars writes `vars` in the format of GITHUB_OUTPUT and GITHUB_ENV files: a KEY=value line
+// for each, or for values with more than one line, KEY<<DELIMITER followed by the lines and the
+// delimiter.
+func writeGitHubVars(w io.Writer, vars [][2]string) error {
+	for _, kv := range vars {
+		var err error
+		switch {
+		case !strings.ContainsAny(kv[1], "\r\n"):
+			_, err = fmt.Fprintf(w, "%s=%s\n", kv[0], kv[1])
+		case strings.Contains(kv[1], gitHubDelimiter):
+			err = fmt.Errorf("value of %s contains the delimiter %s", kv[0], gitHubDelimiter)
+		default:
+			_, err = fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", kv[0], gitHubDelimiter, kv[1], gitHubDelimiter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, versions *gitversion.VersionDetails) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewVersionDetailsJSON(versions))
+}
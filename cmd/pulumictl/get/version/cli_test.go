@@ -0,0 +1,63 @@
+package version
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendGitHubFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github_output")
+	require.NoError(t, os.WriteFile(path, []byte("EARLIER=step\n"), 0o600))
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	versions := &gitversion.VersionDetails{
+		LanguageVersions: gitversion.LanguageVersions{
+			SemVer:     "1.2.3",
+			Python:     "1.2.3",
+			JavaScript: "v1.2.3",
+			DotNet:     "1.2.3",
+			Java:       "1.2.3",
+		},
+		ShortHash: "abcdef12",
+		Timestamp: time.Unix(1697040000, 0),
+	}
+	require.NoError(t, appendGitHubFile("GITHUB_OUTPUT", versions))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `EARLIER=step
+VERSION=1.2.3
+PYTHON_VERSION=1.2.3
+JAVASCRIPT_VERSION=v1.2.3
+DOTNET_VERSION=1.2.3
+JAVA_VERSION=1.2.3
+BASE_TAG=
+IS_EXACT=false
+IS_DIRTY=false
+SHORT_HASH=abcdef12
+COMMIT_TIMESTAMP=1697040000
+`, string(content))
+
+	t.Setenv("GITHUB_OUTPUT", "")
+	require.EqualError(t, appendGitHubFile("GITHUB_OUTPUT", versions),
+		"$GITHUB_OUTPUT is not set, are we running in GitHub Actions?")
+}
+
+func TestWriteGitHubVars(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeGitHubVars(&buf, [][2]string{
+		{"EMPTY", ""},
+		{"MULTI", "first\nsecond"},
+		{"SINGLE", "value"},
+	}))
+	require.Equal(t, "EMPTY=\nMULTI<<PULUMICTL_EOF\nfirst\nsecond\nPULUMICTL_EOF\nSINGLE=value\n", buf.String())
+
+	err := writeGitHubVars(&buf, [][2]string{{"BAD", "a\nPULUMICTL_EOF\nb"}})
+	require.EqualError(t, err, "value of BAD contains the delimiter PULUMICTL_EOF")
+}
@@ -0,0 +1,75 @@
+package version
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	gh "github.com/pulumi/pulumictl/pkg/github"
+	viperlib "github.com/spf13/viper"
+)
+
+// shallowFallback returns a function listing the tags to fall back to when the repository is a
+// shallow clone, gathered from an explicit tag, a file of tags and the GitHub tags API. It returns
+// nil if no fallback sources are configured.
+func shallowFallback(tag, tagsFile, githubRepo string) func() ([]string, error) {
+	if tag == "" && tagsFile == "" && githubRepo == "" {
+		return nil
+	}
+
+	return func() ([]string, error) {
+		var tags []string
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+
+		if tagsFile != "" {
+			fileTags, err := readTagsFile(tagsFile)
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, fileTags...)
+		}
+
+		if githubRepo != "" {
+			parts := strings.Split(githubRepo, "/")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("unable to use repo: format must be <org>/<repo> - value: %s", githubRepo)
+			}
+
+			ctx, client := gh.CreateGithubClient(viperlib.GetString("token"))
+			githubTags, err := gh.ListTagNames(ctx, client, parts[0], parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("unable to list tags from GitHub: %w", err)
+			}
+			tags = append(tags, githubTags...)
+		}
+
+		return tags, nil
+	}
+}
+
+// readTagsFile reads one tag per line from `path`. Only the last field of each line is used, so
+// the output of `git ls-remote --tags` can be used directly.
+func readTagsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening tags file: %w", err)
+	}
+	defer f.Close()
+
+	var tags []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		tags = append(tags, strings.TrimSuffix(fields[len(fields)-1], "^{}"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading tags file: %w", err)
+	}
+	return tags, nil
+}
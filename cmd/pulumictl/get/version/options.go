@@ -0,0 +1,225 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
+)
+
+// AddOptionFlags adds the flags which control how versions are calculated from the repository to
+// `command`, and binds them to `viper`. Commands which calculate versions the way `get version`
+// does, such as `set-version`, share them so that they agree on the version of a checkout.
+func AddOptionFlags(command *cobra.Command, viper *viperlib.Viper) {
+	command.Flags().StringP("repo", "r", "", "path to repository, defaults to current working directory")
+	command.Flags().String("version-prefix", "", "the version prefix (e.g. 3.0.0). Must be valid semver.")
+	command.Flags().BoolP("omit-commit-hash", "o", false, "whether to include or omit the commit hash in the version")
+	command.Flags().Bool("is-prerelease", false, "whether this is a pre-release version")
+	command.Flags().String("tag-pattern", "", "regex pattern to filter tags with (e.g. ^sdk/)")
+	command.Flags().StringSlice("prerelease-policy", nil,
+		"whether tags with a prerelease identifier are used as the base tag, as <identifier>=<policy> where "+
+			"policy is release, prerelease (only with --is-prerelease) or never. Defaults to beta=prerelease,rc=prerelease")
+	command.Flags().String("bump-strategy", "default",
+		"how to bump the version past the most recent tag (default or conventional)")
+	command.Flags().String("base-strategy", "preorder",
+		"how to pick the tag to base the version on when HEAD is not tagged (preorder, nearest or highest-reachable)")
+	command.Flags().Bool("first-parent", false,
+		"only follow the first parent of merge commits when looking for the base tag")
+	command.Flags().StringSlice("release-branch-pattern", nil,
+		"a doublestar glob for maintenance branches, on which versions bump the patch version, e.g. "+
+			strings.Join(gitversion.DefaultReleaseBranchPatterns, ", ")+". May be repeated")
+	command.Flags().String("branch", "",
+		"the branch being versioned, for CI systems which check out a detached HEAD. Defaults to $PULUMICTL_BRANCH")
+	command.Flags().String("prerelease-number", "timestamp",
+		"the number used in prerelease versions past a tag (timestamp, distance or distance-timestamp)")
+	command.Flags().String("timestamp", "",
+		"the timestamp for prerelease versions, as seconds since the epoch or RFC 3339, instead of the commit time. "+
+			"Defaults to $SOURCE_DATE_EPOCH")
+	command.Flags().String("timestamp-source", "committer",
+		"which commit time to use for prerelease versions (committer, or author to be stable across rebases)")
+	command.Flags().String("on-shallow", "error",
+		"what to do when a shallow clone has no tags in its history (error, ignore, fallback or fetch)")
+	command.Flags().String("fallback-tag", "", "a tag to fall back to with --on-shallow=fallback")
+	command.Flags().String("fallback-tags-file", "",
+		"a file listing tags to fall back to with --on-shallow=fallback, one per line")
+	command.Flags().String("fallback-github-repo", "",
+		"a GitHub repository (<org>/<repo>) whose tags to fall back to with --on-shallow=fallback")
+	command.Flags().StringSlice("path", nil,
+		"only consider changes to files under this path, relative to the repository root. May be repeated")
+	command.Flags().StringSlice("dirty-ignore", nil,
+		"a doublestar glob for files whose changes don't make the version dirty (e.g. bin/**). May be repeated")
+	command.Flags().Bool("explain", false,
+		"print how the version was derived to stderr, including which tags were considered or skipped")
+	command.Flags().String("satisfies", "",
+		"a semver range (e.g. \">=3.0.0 <4.0.0\") the version must be in, failing otherwise. Prereleases are checked "+
+			"as the release they lead up to")
+
+	util.NoErr(viper.BindPFlag("repo", command.Flags().Lookup("repo")))
+	config.MarkRepoFlag(command.Flags(), "repo")
+
+	util.NoErr(viper.BindPFlag("omit-commit-hash", command.Flags().Lookup("omit-commit-hash")))
+
+	util.NoErr(viper.BindEnv("version-prefix", "VERSION_PREFIX"))
+	util.NoErr(viper.BindPFlag("version-prefix", command.Flags().Lookup("version-prefix")))
+
+	util.NoErr(viper.BindEnv("is-prerelease", "IS_PRERELEASE"))
+	util.NoErr(viper.BindPFlag("is-prerelease", command.Flags().Lookup("is-prerelease")))
+
+	util.NoErr(viper.BindEnv("tag-pattern", "TAG_PATTERN"))
+	util.NoErr(viper.BindPFlag("tag-pattern", command.Flags().Lookup("tag-pattern")))
+
+	util.NoErr(viper.BindEnv("prerelease-policy", "PRERELEASE_POLICY"))
+	util.NoErr(viper.BindPFlag("prerelease-policy", command.Flags().Lookup("prerelease-policy")))
+
+	util.NoErr(viper.BindEnv("bump-strategy", "BUMP_STRATEGY"))
+	util.NoErr(viper.BindPFlag("bump-strategy", command.Flags().Lookup("bump-strategy")))
+
+	util.NoErr(viper.BindEnv("base-strategy", "BASE_STRATEGY"))
+	util.NoErr(viper.BindPFlag("base-strategy", command.Flags().Lookup("base-strategy")))
+
+	util.NoErr(viper.BindEnv("first-parent", "FIRST_PARENT"))
+	util.NoErr(viper.BindPFlag("first-parent", command.Flags().Lookup("first-parent")))
+
+	util.NoErr(viper.BindEnv("release-branch-pattern", "RELEASE_BRANCH_PATTERN"))
+	util.NoErr(viper.BindPFlag("release-branch-pattern", command.Flags().Lookup("release-branch-pattern")))
+
+	util.NoErr(viper.BindEnv("branch", "PULUMICTL_BRANCH"))
+	util.NoErr(viper.BindPFlag("branch", command.Flags().Lookup("branch")))
+
+	util.NoErr(viper.BindEnv("prerelease-number", "PRERELEASE_NUMBER"))
+	util.NoErr(viper.BindPFlag("prerelease-number", command.Flags().Lookup("prerelease-number")))
+
+	util.NoErr(viper.BindEnv("timestamp", "SOURCE_DATE_EPOCH"))
+	util.NoErr(viper.BindPFlag("timestamp", command.Flags().Lookup("timestamp")))
+
+	util.NoErr(viper.BindEnv("timestamp-source", "TIMESTAMP_SOURCE"))
+	util.NoErr(viper.BindPFlag("timestamp-source", command.Flags().Lookup("timestamp-source")))
+
+	util.NoErr(viper.BindEnv("on-shallow", "ON_SHALLOW"))
+	util.NoErr(viper.BindPFlag("on-shallow", command.Flags().Lookup("on-shallow")))
+
+	util.NoErr(viper.BindEnv("fallback-tag", "FALLBACK_TAG"))
+	util.NoErr(viper.BindPFlag("fallback-tag", command.Flags().Lookup("fallback-tag")))
+	util.NoErr(viper.BindPFlag("fallback-tags-file", command.Flags().Lookup("fallback-tags-file")))
+	util.NoErr(viper.BindPFlag("fallback-github-repo", command.Flags().Lookup("fallback-github-repo")))
+
+	util.NoErr(viper.BindPFlag("path", command.Flags().Lookup("path")))
+
+	util.NoErr(viper.BindEnv("dirty-ignore", "DIRTY_IGNORE"))
+	util.NoErr(viper.BindPFlag("dirty-ignore", command.Flags().Lookup("dirty-ignore")))
+
+	util.NoErr(viper.BindPFlag("explain", command.Flags().Lookup("explain")))
+
+	util.NoErr(viper.BindEnv("satisfies", "VERSION_SATISFIES"))
+	util.NoErr(viper.BindPFlag("satisfies", command.Flags().Lookup("satisfies")))
+}
+
+// Options opens the repository and returns the options to calculate the version of `commitish`
+// with, from the flags added by AddOptionFlags.
+func Options(viper *viperlib.Viper, commitish string) (gitversion.LanguageVersionsOptions, error) {
+	var opts gitversion.LanguageVersionsOptions
+
+	workingDir := viper.GetString("repo")
+	if workingDir == "" {
+		var err error
+		workingDir, err = os.Getwd()
+		if err != nil {
+			return opts, fmt.Errorf("error obtaining working directory: %w", err)
+		}
+	}
+
+	bump, err := gitversion.ParseBumpStrategy(viper.GetString("bump-strategy"))
+	if err != nil {
+		return opts, err
+	}
+
+	base, err := gitversion.ParseBaseStrategy(viper.GetString("base-strategy"))
+	if err != nil {
+		return opts, err
+	}
+
+	number, err := gitversion.ParsePreReleaseNumber(viper.GetString("prerelease-number"))
+	if err != nil {
+		return opts, err
+	}
+
+	shallowPolicy, err := gitversion.ParseShallowPolicy(viper.GetString("on-shallow"))
+	if err != nil {
+		return opts, err
+	}
+
+	policies, err := gitversion.ParseTagPolicies(viper.GetStringSlice("prerelease-policy"))
+	if err != nil {
+		return opts, err
+	}
+
+	source, err := gitversion.ParseTimestampSource(viper.GetString("timestamp-source"))
+	if err != nil {
+		return opts, err
+	}
+
+	var fixedTime time.Time
+	if timestamp := viper.GetString("timestamp"); timestamp != "" {
+		if fixedTime, err = gitversion.ParseTimestamp(timestamp); err != nil {
+			return opts, err
+		}
+	}
+
+	var tagFilter func(string) bool
+	if tagPattern := viper.GetString("tag-pattern"); tagPattern != "" {
+		re, err := regexp.Compile(tagPattern)
+		if err != nil {
+			return opts, fmt.Errorf("tag-pattern not a valid regexp: %w", err)
+		}
+		tagFilter = func(tag string) bool {
+			return re.MatchString(tag)
+		}
+	}
+
+	repo, err := git.PlainOpenWithOptions(workingDir, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true})
+	if err != nil {
+		return opts, fmt.Errorf("error opening repository: %w", err)
+	}
+
+	var explainWriter io.Writer
+	if viper.GetBool("explain") {
+		explainWriter = os.Stderr
+	}
+
+	return gitversion.LanguageVersionsOptions{
+		Repo:                  repo,
+		Commitish:             plumbing.Revision(commitish),
+		OmitCommitHash:        viper.GetBool("omit-commit-hash"),
+		ReleasePrefix:         viper.GetString("version-prefix"),
+		IsPreRelease:          viper.GetBool("is-prerelease"),
+		TagFilter:             tagFilter,
+		PreReleaseTagPolicies: policies,
+		BumpStrategy:          bump,
+		BaseStrategy:          base,
+		FirstParent:           viper.GetBool("first-parent"),
+		PreReleaseNumber:      number,
+		Timestamp:             fixedTime,
+		TimestampSource:       source,
+		OnShallow:             shallowPolicy,
+		ShallowFallback: shallowFallback(viper.GetString("fallback-tag"),
+			viper.GetString("fallback-tags-file"), viper.GetString("fallback-github-repo")),
+		Paths:                 viper.GetStringSlice("path"),
+		DirtyIgnore:           viper.GetStringSlice("dirty-ignore"),
+		Explain:               explainWriter,
+		ReleaseBranchPatterns: viper.GetStringSlice("release-branch-pattern"),
+		Branch:                viper.GetString("branch"),
+		Satisfies:             viper.GetString("satisfies"),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package version
+
+import (
+	"time"
+
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+)
+
+// LanguageVersionsJSON is the JSON form of gitversion.LanguageVersions written by pulumictl
+// commands. The library types have no JSON tags, so that programs marshalling them keep their
+// field names.
+type LanguageVersionsJSON struct {
+	SemVer     string `json:"semver"`
+	Python     string `json:"python"`
+	JavaScript string `json:"javascript"`
+	DotNet     string `json:"dotnet"`
+	Java       string `json:"java"`
+}
+
+// NewLanguageVersionsJSON returns the JSON form of `versions`.
+func NewLanguageVersionsJSON(versions *gitversion.LanguageVersions) *LanguageVersionsJSON {
+	return &LanguageVersionsJSON{
+		SemVer:     versions.SemVer,
+		Python:     versions.Python,
+		JavaScript: versions.JavaScript,
+		DotNet:     versions.DotNet,
+		Java:       versions.Java,
+	}
+}
+
+// VersionDetailsJSON is the JSON form of gitversion.VersionDetails written by pulumictl commands.
+type VersionDetailsJSON struct {
+	*LanguageVersionsJSON
+
+	BaseTag    string    `json:"baseTag"`
+	IsExact    bool      `json:"isExact"`
+	Dirty      bool      `json:"dirty"`
+	ShortHash  string    `json:"shortHash"`
+	Timestamp  time.Time `json:"timestamp"`
+	DirtyFiles []string  `json:"dirtyFiles,omitempty"`
+}
+
+// NewVersionDetailsJSON returns the JSON form of `details`.
+func NewVersionDetailsJSON(details *gitversion.VersionDetails) *VersionDetailsJSON {
+	return &VersionDetailsJSON{
+		LanguageVersionsJSON: NewLanguageVersionsJSON(&details.LanguageVersions),
+		BaseTag:              details.BaseTag,
+		IsExact:              details.IsExact,
+		Dirty:                details.Dirty,
+		ShortHash:            details.ShortHash,
+		Timestamp:            details.Timestamp,
+		DirtyFiles:           details.DirtyFiles,
+	}
+}
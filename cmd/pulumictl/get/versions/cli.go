@@ -0,0 +1,148 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pulumi/pulumictl/cmd/pulumictl/get/version"
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
+)
+
+var (
+	allModules bool
+	modules    []string
+	output     string
+)
+
+// rootModule is how the module tagged "vX.Y.Z", without a path, is displayed.
+const rootModule = "."
+
+func Command(registry *config.Registry) *cobra.Command {
+	viper := viperlib.New()
+	command := &cobra.Command{
+		Use:   "versions",
+		Short: "Calculate versions for several modules",
+		Long: "Calculate package versions for each module of a repository which tags releases as " +
+			"<module>/vX.Y.Z, such as sdk/v3.0.0, walking history once for all modules",
+		Args: cobra.MaximumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.ParseFlags(args); err != nil {
+				return err
+			}
+
+			commitish := "HEAD"
+			if len(args) == 1 {
+				commitish = args[0]
+			}
+
+			allModules = viper.GetBool("all-modules")
+			modules = viper.GetStringSlice("module")
+			output = viper.GetString("output")
+
+			if !allModules && len(modules) == 0 {
+				return fmt.Errorf("one of --all-modules or --module must be specified")
+			}
+
+			opts, err := version.Options(viper, commitish)
+			if err != nil {
+				return err
+			}
+			// Each module's base tag is the first one found walking history, so only preorder applies
+			if opts.BaseStrategy != gitversion.BaseStrategyPreorder {
+				return fmt.Errorf("--base-strategy %s is not supported for multiple modules, only preorder",
+					opts.BaseStrategy)
+			}
+
+			versions, err := gitversion.GetModuleVersionsWithOptions(opts)
+			if err != nil {
+				return fmt.Errorf("error calculating versions: %w", err)
+			}
+
+			byModule := map[string]*gitversion.VersionDetails{}
+			for module, details := range versions {
+				if module == "" {
+					module = rootModule
+				}
+				byModule[module] = details
+			}
+
+			if !allModules {
+				selected := map[string]*gitversion.VersionDetails{}
+				for _, module := range modules {
+					module = strings.Trim(module, "/")
+					if module == "" {
+						module = rootModule
+					}
+					details, ok := byModule[module]
+					if !ok {
+						return fmt.Errorf("no tags found for module %q", module)
+					}
+					selected[module] = details
+				}
+				byModule = selected
+			}
+
+			switch strings.ToLower(output) {
+			case "", "table":
+				return writeTable(os.Stdout, byModule)
+			case "json":
+				byModuleJSON := map[string]*version.VersionDetailsJSON{}
+				for module, details := range byModule {
+					byModuleJSON[module] = version.NewVersionDetailsJSON(details)
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(byModuleJSON)
+			default:
+				return fmt.Errorf("invalid output format %q", output)
+			}
+		},
+	}
+
+	command.Flags().BoolVar(&allModules, "all-modules", false,
+		"calculate versions for every module discovered from the repository tags")
+	command.Flags().StringSliceVar(&modules, "module", nil,
+		"a module path to calculate the version of (e.g. sdk), or . for the root module. May be repeated")
+	command.Flags().StringVar(&output, "output", "table", "the output format (table or json)")
+	version.AddOptionFlags(command, viper)
+
+	util.NoErr(viper.BindPFlag("all-modules", command.Flags().Lookup("all-modules")))
+	util.NoErr(viper.BindPFlag("module", command.Flags().Lookup("module")))
+	util.NoErr(viper.BindPFlag("output", command.Flags().Lookup("output")))
+
+	// Versions are calculated from the settings for `get version`, so that both commands agree
+	registry.Register("get.versions", viper, "get.version")
+
+	return command
+}
+
+func writeTable(w io.Writer, versions map[string]*gitversion.VersionDetails) error {
+	names := make([]string, 0, len(versions))
+	for module := range versions {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "MODULE\tBASE TAG\tVERSION\tPYTHON\tJAVASCRIPT\tDOTNET\tJAVA")
+	for _, module := range names {
+		v := versions[module]
+		baseTag := v.BaseTag
+		if baseTag == "" {
+			baseTag = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			module, baseTag, v.SemVer, v.Python, v.JavaScript, v.DotNet, v.Java)
+	}
+	return table.Flush()
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/spf13/cobra"
 	viperlib "github.com/spf13/viper"
 
+	bump_version "github.com/pulumi/pulumictl/cmd/pulumictl/bump-version"
+	compare_version "github.com/pulumi/pulumictl/cmd/pulumictl/compare-version"
+	"github.com/pulumi/pulumictl/cmd/pulumictl/config"
 	convert_version "github.com/pulumi/pulumictl/cmd/pulumictl/convert-version"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/copyright"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/cover"
@@ -17,7 +20,9 @@ import (
 	"github.com/pulumi/pulumictl/cmd/pulumictl/dispatch"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/generate"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/get"
+	set_version "github.com/pulumi/pulumictl/cmd/pulumictl/set-version"
 	"github.com/pulumi/pulumictl/cmd/pulumictl/version"
+	cfg "github.com/pulumi/pulumictl/pkg/config"
 	"github.com/pulumi/pulumictl/pkg/contract"
 	"github.com/pulumi/pulumictl/pkg/util"
 )
@@ -25,38 +30,68 @@ import (
 var (
 	githubToken string
 	debug       bool
+	configFile  string
 )
 
 func configureCLI() *cobra.Command {
 	// Using the shared global Viper instance for the top-level command. Sub-commands should use viperlib.New().
 	viper := viperlib.GetViper()
+	registry := cfg.NewRegistry()
 
 	rootCommand := &cobra.Command{
 		Use:  "pulumictl",
 		Long: "A swiss army knife for Pulumi development",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return loadConfig(registry, viper.GetString("config"), cfg.RepoDir(cmd.Flags()))
+		},
 	}
 
-	rootCommand.AddCommand(get.Command())
-	rootCommand.AddCommand(create.Command())
+	rootCommand.AddCommand(get.Command(registry))
+	rootCommand.AddCommand(create.Command(registry))
 	rootCommand.AddCommand(version.Command())
-	rootCommand.AddCommand(dispatch.Command())
-	rootCommand.AddCommand(copyright.Command())
+	rootCommand.AddCommand(dispatch.Command(registry))
+	rootCommand.AddCommand(copyright.Command(registry))
 	rootCommand.AddCommand(generate.Command())
 	rootCommand.AddCommand(cover.Command())
 	rootCommand.AddCommand(winget.Command())
 	rootCommand.AddCommand(download_binary.Command())
-	rootCommand.AddCommand(convert_version.Command())
+	rootCommand.AddCommand(convert_version.Command(registry))
+	rootCommand.AddCommand(compare_version.Command(registry))
+	rootCommand.AddCommand(bump_version.Command(registry))
+	rootCommand.AddCommand(config.Command(registry))
+	rootCommand.AddCommand(set_version.Command(registry))
 
 	rootCommand.PersistentFlags().StringVarP(&githubToken,
 		"token", "t", "", "a github token to use for making API calls to GitHub.")
 	rootCommand.PersistentFlags().BoolVarP(&debug, "debug", "D", false, "enable debug logging")
+	rootCommand.PersistentFlags().StringVar(&configFile, "config", "",
+		"a config file to use instead of the "+cfg.FileName+" at the root of the repository")
 	util.NoErr(viper.BindEnv("debug", "PULUMICTL_DEBUG"))
 	util.NoErr(viper.BindEnv("token", "GITHUB_TOKEN"))
 	util.NoErr(viper.BindPFlag("debug", rootCommand.PersistentFlags().Lookup("debug")))
+	util.NoErr(viper.BindEnv("config", "PULUMICTL_CONFIG"))
+	util.NoErr(viper.BindPFlag("config", rootCommand.PersistentFlags().Lookup("config")))
 
 	return rootCommand
 }
 
+// loadConfig loads the config file at `path`, or if it is empty the config file at the root of the
+// repository containing `repoDir`, or the working directory if that is empty, if there is one.
+func loadConfig(registry *cfg.Registry, path, repoDir string) error {
+	if path == "" {
+		var err error
+		if repoDir == "" {
+			if repoDir, err = os.Getwd(); err != nil {
+				return fmt.Errorf("error obtaining working directory: %w", err)
+			}
+		}
+		if path, err = cfg.Find(repoDir); err != nil || path == "" {
+			return err
+		}
+	}
+	return registry.Load(path)
+}
+
 func main() {
 	rootCommand := configureCLI()
 
@@ -0,0 +1,99 @@
+package setversion
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumictl/cmd/pulumictl/get/version"
+	"github.com/pulumi/pulumictl/pkg/config"
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/pulumi/pulumictl/pkg/manifest"
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/cobra"
+	viperlib "github.com/spf13/viper"
+)
+
+func Command(registry *config.Registry) *cobra.Command {
+	viper := viperlib.New()
+	command := &cobra.Command{
+		Use:   "set-version <manifest>...",
+		Short: "Write versions into package manifests",
+		Long: "Write the version calculated from repository tags and state, or an explicit --version, into " +
+			"package.json, pyproject.toml, setup.py, *.csproj, pom.xml and version.go files, in the form " +
+			"expected by each language. With --check the files are left untouched, and the command fails " +
+			"if any of them do not match.",
+		Args: cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := languageVersions(viper)
+			if err != nil {
+				return err
+			}
+
+			check := viper.GetBool("check")
+			var mismatched int
+			for _, path := range args {
+				m, err := manifest.Read(path)
+				if err != nil {
+					return err
+				}
+
+				want := m.Want(versions)
+				switch {
+				case m.Current == want:
+					fmt.Printf("%s: %s\n", path, want)
+				case check:
+					mismatched++
+					fmt.Printf("%s: %s, expected %s\n", path, m.Current, want)
+				default:
+					fmt.Printf("%s: %s -> %s\n", path, m.Current, want)
+					if err := m.Write(want); err != nil {
+						return err
+					}
+				}
+			}
+
+			if mismatched > 0 {
+				return fmt.Errorf("%d of %d manifests do not match version %s", mismatched, len(args),
+					versions.SemVer)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringP("version", "v", "",
+		"the generic version to write (e.g. 3.0.0), instead of calculating it from the repository")
+	command.Flags().Bool("check", false, "check that the manifests hold the version without changing them")
+	version.AddOptionFlags(command, viper)
+
+	util.NoErr(viper.BindEnv("version", "VERSION"))
+	util.NoErr(viper.BindPFlag("version", command.Flags().Lookup("version")))
+
+	util.NoErr(viper.BindPFlag("check", command.Flags().Lookup("check")))
+
+	// Versions are calculated from the settings for `get version`, so that both commands agree
+	registry.Register("set-version", viper, "get.version")
+
+	return command
+}
+
+// languageVersions converts the explicit version, if one was given, or calculates the version of
+// HEAD in the repository as `get version` does.
+func languageVersions(viper *viperlib.Viper) (*gitversion.LanguageVersions, error) {
+	if explicit := viper.GetString("version"); explicit != "" {
+		versions, err := gitversion.GetLanguageOptionsFromVersion(explicit)
+		if err != nil {
+			return nil, fmt.Errorf("error converting version: %w", err)
+		}
+		return versions, nil
+	}
+
+	opts, err := version.Options(viper, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	versions, err := gitversion.GetLanguageVersionsWithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating version: %w", err)
+	}
+	return versions, nil
+}
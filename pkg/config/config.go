@@ -0,0 +1,193 @@
+// Package config loads defaults for pulumictl commands from a .pulumictl.yaml file at the root of
+// the repository given by the command's `--repo` flag, or containing the working directory.
+// Settings are namespaced by command, so that
+//
+//	get:
+//	  version:
+//	    tag-pattern: ^sdk/
+//	copyright:
+//	  exclude: ["sdk/python/**"]
+//
+// sets `--tag-pattern` for `pulumictl get version` and `--exclude` for `pulumictl copyright`.
+// Flags and environment variables take precedence over the file.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumictl/pkg/util"
+	"github.com/spf13/pflag"
+	viperlib "github.com/spf13/viper"
+)
+
+// FileName is the name of the config file looked for at the root of the repository.
+const FileName = ".pulumictl.yaml"
+
+// repoAnnotation marks the flag holding the path to the repository a command works on.
+const repoAnnotation = "pulumictl_repo"
+
+// Registry holds the Viper instance of each command, keyed by namespace. The root command creates
+// one and passes it to the commands which take their defaults from the config file.
+type Registry struct {
+	vipers map[string]*viperlib.Viper
+	// inherits holds the namespaces whose settings a namespace also takes, by namespace.
+	inherits map[string][]string
+	// loaded is the path of the config file which was loaded, if any.
+	loaded string
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{vipers: map[string]*viperlib.Viper{}, inherits: map[string][]string{}}
+}
+
+// Register makes the settings under `namespace` in the config file, e.g. "get.version" for
+// `pulumictl get version`, defaults for the flags and environment variables bound to `viper`.
+// Settings under the `inherits` namespaces, such as "get.version" for a command sharing its flags,
+// are merged first, so that those under `namespace` take precedence. Registering a namespace again
+// replaces its Viper instance.
+func (r *Registry) Register(namespace string, viper *viperlib.Viper, inherits ...string) {
+	r.vipers[namespace] = viper
+	r.inherits[namespace] = inherits
+}
+
+// Namespaces returns the registered namespaces in order.
+func (r *Registry) Namespaces() []string {
+	namespaces := make([]string, 0, len(r.vipers))
+	for namespace := range r.vipers {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Settings returns the effective settings of the command registered under `namespace`, after
+// merging flags, environment variables, the config file and defaults.
+func (r *Registry) Settings(namespace string) (map[string]interface{}, error) {
+	viper, ok := r.vipers[namespace]
+	if !ok {
+		return nil, fmt.Errorf("unknown config namespace %q, expected one of %s",
+			namespace, strings.Join(r.Namespaces(), ", "))
+	}
+
+	settings := map[string]interface{}{}
+	for _, key := range viper.AllKeys() {
+		settings[key] = viper.Get(key)
+	}
+	return settings, nil
+}
+
+// MarkRepoFlag marks the flag `name` in `flags` as the path to the repository the command works on,
+// such as `--repo`, so that the config file is looked for in that repository rather than the one
+// containing the working directory.
+func MarkRepoFlag(flags *pflag.FlagSet, name string) {
+	util.NoErr(flags.SetAnnotation(name, repoAnnotation, []string{"true"}))
+}
+
+// RepoDir returns the value of the flag in `flags` marked by MarkRepoFlag, or "" if there is none.
+func RepoDir(flags *pflag.FlagSet) string {
+	var dir string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if _, ok := flag.Annotations[repoAnnotation]; ok {
+			dir = flag.Value.String()
+		}
+	})
+	return dir
+}
+
+// Find returns the path of the config file at the root of the repository containing `dir`, or ""
+// if there is none, or `dir` is not in a repository.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			path := filepath.Join(dir, FileName)
+			if _, err := os.Stat(path); err != nil {
+				if os.IsNotExist(err) {
+					return "", nil
+				}
+				return "", err
+			}
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads the config file at `path` and merges the settings for each registered namespace into
+// its Viper instance. Settings for commands which are not registered are an error, as they are
+// most likely a typo.
+func (r *Registry) Load(path string) error {
+	file := viperlib.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := r.checkNamespaces(file.AllSettings(), ""); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for namespace, viper := range r.vipers {
+		for _, section := range append(r.inherits[namespace], namespace) {
+			sub := file.Sub(section)
+			if sub == nil {
+				continue
+			}
+			if err := viper.MergeConfigMap(sub.AllSettings()); err != nil {
+				return fmt.Errorf("%s: merging %q into %q: %w", path, section, namespace, err)
+			}
+		}
+	}
+	r.loaded = path
+	return nil
+}
+
+// File returns the path of the config file which was loaded, or "" if none was.
+func (r *Registry) File() string {
+	return r.loaded
+}
+
+// checkNamespaces returns an error if `settings`, found under `prefix`, names a command which is
+// not registered.
+func (r *Registry) checkNamespaces(settings map[string]interface{}, prefix string) error {
+	for key, value := range settings {
+		namespace := strings.TrimPrefix(prefix+"."+key, ".")
+		if _, ok := r.vipers[namespace]; ok {
+			continue
+		}
+
+		nested, ok := value.(map[string]interface{})
+		if !ok || !r.hasNamespacePrefix(namespace+".") {
+			return fmt.Errorf("unknown config namespace %q, expected one of %s",
+				namespace, strings.Join(r.Namespaces(), ", "))
+		}
+		if err := r.checkNamespaces(nested, namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasNamespacePrefix returns whether any registered namespace starts with `prefix`.
+func (r *Registry) hasNamespacePrefix(prefix string) bool {
+	for namespace := range r.vipers {
+		if strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	viperlib "github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	flags := pflag.NewFlagSet("version", pflag.ContinueOnError)
+	flags.String("tag-pattern", "", "")
+	flags.String("version-prefix", "", "")
+	flags.String("language", "generic", "")
+	flags.StringSlice("path", nil, "")
+
+	viper := viperlib.New()
+	for _, name := range []string{"tag-pattern", "version-prefix", "language", "path"} {
+		require.NoError(t, viper.BindPFlag(name, flags.Lookup(name)))
+	}
+	require.NoError(t, viper.BindEnv("version-prefix", "TEST_CONFIG_VERSION_PREFIX"))
+	registry := NewRegistry()
+	registry.Register("test.version", viper)
+	registry.Register("test.other", viperlib.New())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	require.NoError(t, os.WriteFile(path, []byte(`
+test:
+  version:
+    tag-pattern: ^sdk/
+    version-prefix: 3.0.0
+    language: python
+    path: [sdk, provider]
+`), 0o600))
+
+	require.NoError(t, flags.Parse([]string{"--language", "dotnet"}))
+	t.Setenv("TEST_CONFIG_VERSION_PREFIX", "4.0.0")
+	require.NoError(t, registry.Load(path))
+	require.Equal(t, path, registry.File())
+
+	require.Equal(t, "^sdk/", viper.GetString("tag-pattern"))
+	require.Equal(t, "4.0.0", viper.GetString("version-prefix"), "environment variables override the file")
+	require.Equal(t, "dotnet", viper.GetString("language"), "flags override the file")
+	require.Equal(t, []string{"sdk", "provider"}, viper.GetStringSlice("path"))
+
+	settings, err := registry.Settings("test.version")
+	require.NoError(t, err)
+	require.Equal(t, "^sdk/", settings["tag-pattern"])
+	require.Equal(t, "dotnet", settings["language"])
+
+	_, err = registry.Settings("test.missing")
+	require.Error(t, err)
+
+	t.Run("Unknown namespace", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("test:\n  verison:\n    tag-pattern: x\n"), 0o600))
+		require.ErrorContains(t, registry.Load(path), `unknown config namespace "test.verison"`)
+
+		require.NoError(t, os.WriteFile(path, []byte("tset:\n  version:\n    tag-pattern: x\n"), 0o600))
+		require.ErrorContains(t, registry.Load(path), `unknown config namespace "tset"`)
+	})
+}
+
+func TestRegisterReplaces(t *testing.T) {
+	registry := NewRegistry()
+	first, second := viperlib.New(), viperlib.New()
+	registry.Register("test.version", first)
+	registry.Register("test.version", second)
+	require.Equal(t, []string{"test.version"}, registry.Namespaces())
+
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("test:\n  version:\n    tag-pattern: ^sdk/\n"), 0o600))
+	require.NoError(t, registry.Load(path))
+	require.Equal(t, "^sdk/", second.GetString("tag-pattern"))
+	require.Empty(t, first.GetString("tag-pattern"))
+}
+
+func TestRegisterInherits(t *testing.T) {
+	registry := NewRegistry()
+	version, set := viperlib.New(), viperlib.New()
+	registry.Register("test.version", version)
+	registry.Register("test-set", set, "test.version")
+
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(`
+test:
+  version:
+    tag-pattern: ^sdk/
+    version-prefix: 3.0.0
+test-set:
+  version-prefix: 4.0.0
+`), 0o600))
+	require.NoError(t, registry.Load(path))
+
+	require.Equal(t, "^sdk/", set.GetString("tag-pattern"))
+	require.Equal(t, "4.0.0", set.GetString("version-prefix"), "the command's own settings take precedence")
+	require.Equal(t, "3.0.0", version.GetString("version-prefix"))
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "sdk", "go")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+
+	path, err := Find(nested)
+	require.NoError(t, err)
+	require.Empty(t, path, "not in a repository")
+
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o700))
+	path, err = Find(nested)
+	require.NoError(t, err)
+	require.Empty(t, path, "no config file")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, FileName), nil, 0o600))
+	path, err = Find(nested)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, FileName), path)
+}
+
+func TestRepoDir(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("repo", "", "")
+	require.Empty(t, RepoDir(flags))
+
+	require.NoError(t, flags.Parse([]string{"--repo", "../provider"}))
+	require.Empty(t, RepoDir(flags), "not marked")
+
+	MarkRepoFlag(flags, "repo")
+	require.Equal(t, "../provider", RepoDir(flags))
+}
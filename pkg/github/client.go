@@ -22,3 +22,22 @@ func CreateGithubClient(token string) (context.Context, *github.Client) {
 	}
 	return ctx, github.NewClient(tokenClient)
 }
+
+// ListTagNames returns the names of every tag in the GitHub repository `owner/repo`.
+func ListTagNames(ctx context.Context, client *github.Client, owner, repo string) ([]string, error) {
+	var names []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, resp, err := client.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			names = append(names, tag.GetName())
+		}
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
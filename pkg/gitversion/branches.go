@@ -0,0 +1,43 @@
+package gitversion
+
+import (
+	"fmt"
+
+	dstar "github.com/bmatcuk/doublestar"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DefaultReleaseBranchPatterns matches the usual names of maintenance branches, such as
+// "release-3.x", "release/3.45" and "v3.45".
+var DefaultReleaseBranchPatterns = []string{"release-*", "release/**", "v[0-9]*.*"}
+
+// releaseBranch returns the branch being versioned and whether it matches one of
+// `opts.ReleaseBranchPatterns`. The branch is `opts.Branch` if set, for CI systems which check out a
+// detached HEAD, and otherwise the branch checked out in the repository, if any.
+func releaseBranch(opts LanguageVersionsOptions) (string, bool, error) {
+	if len(opts.ReleaseBranchPatterns) == 0 {
+		return "", false, nil
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		head, err := opts.Repo.Reference(plumbing.HEAD, false)
+		if err != nil {
+			return "", false, fmt.Errorf("looking up HEAD: %w", err)
+		}
+		if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+			return "", false, nil
+		}
+		branch = head.Target().Short()
+	}
+
+	for _, pattern := range opts.ReleaseBranchPatterns {
+		if err := checkGlob(pattern); err != nil {
+			return "", false, fmt.Errorf("invalid release branch pattern %q: %w", pattern, err)
+		}
+		if matched, _ := dstar.Match(pattern, branch); matched {
+			return branch, true, nil
+		}
+	}
+	return branch, false, nil
+}
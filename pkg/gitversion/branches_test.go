@@ -0,0 +1,99 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseBranch(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	head, err := testRepoSingleCommit(repo)
+	require.NoError(t, err)
+
+	isRelease := func(t *testing.T, branch string, patterns ...string) bool {
+		_, isRelease, err := releaseBranch(LanguageVersionsOptions{
+			Repo:                  repo,
+			Branch:                branch,
+			ReleaseBranchPatterns: patterns,
+		})
+		require.NoError(t, err)
+		return isRelease
+	}
+
+	for _, branch := range []string{"release-3.x", "release/3.45", "release/v3/hotfix", "v3.45"} {
+		require.True(t, isRelease(t, branch, DefaultReleaseBranchPatterns...), branch)
+	}
+	for _, branch := range []string{"master", "main", "feature/release-notes", "v3", "vnext.1"} {
+		require.False(t, isRelease(t, branch, DefaultReleaseBranchPatterns...), branch)
+	}
+	require.False(t, isRelease(t, "release-3.x"))
+	require.True(t, isRelease(t, "hotfix/3.1", "hotfix/*"))
+
+	t.Run("Checked out branch", func(t *testing.T) {
+		require.False(t, isRelease(t, "", DefaultReleaseBranchPatterns...))
+
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, workTree.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName("release-3.x"),
+			Create: true,
+		}))
+		require.True(t, isRelease(t, "", DefaultReleaseBranchPatterns...))
+
+		require.NoError(t, workTree.Checkout(&git.CheckoutOptions{Hash: head}))
+		require.False(t, isRelease(t, "", DefaultReleaseBranchPatterns...))
+		require.True(t, isRelease(t, "release-3.x", DefaultReleaseBranchPatterns...))
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, _, err := releaseBranch(LanguageVersionsOptions{
+			Repo:                  repo,
+			Branch:                "main",
+			ReleaseBranchPatterns: []string{"release-[3"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestGetVersionReleaseBranch(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v3.45.0"})
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, workTree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName("v3.45"),
+		Create: true,
+	}))
+	addFile(t, workTree, "fix.txt", "fix")
+	_, err = workTree.Commit("feat: a backported feature", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	versionFor := func(t *testing.T, opts LanguageVersionsOptions) string {
+		opts.Repo = repo
+		opts.Commitish = plumbing.Revision("HEAD")
+		opts.OmitCommitHash = true
+		version, err := GetLanguageVersionsWithOptions(opts)
+		require.NoError(t, err)
+		return version.SemVer
+	}
+
+	require.Equal(t, "3.46.0-alpha.0", versionFor(t, LanguageVersionsOptions{}))
+	require.Equal(t, "3.45.1-alpha.0", versionFor(t, LanguageVersionsOptions{
+		ReleaseBranchPatterns: DefaultReleaseBranchPatterns,
+	}))
+	require.Equal(t, "3.45.1-alpha.0", versionFor(t, LanguageVersionsOptions{
+		ReleaseBranchPatterns: DefaultReleaseBranchPatterns,
+		BumpStrategy:          BumpStrategyConventional,
+	}))
+	require.Equal(t, "3.46.0-alpha.0", versionFor(t, LanguageVersionsOptions{
+		ReleaseBranchPatterns: DefaultReleaseBranchPatterns,
+		Branch:                "master",
+	}))
+}
@@ -0,0 +1,147 @@
+package gitversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Increment selects the component of the base version which NextVersion increments.
+type Increment string
+
+const (
+	// IncrementNone keeps the components of the base version, which only makes sense when the base
+	// version is a prerelease: either its release, or its next prerelease, is next.
+	IncrementNone  Increment = ""
+	IncrementMajor Increment = "major"
+	IncrementMinor Increment = "minor"
+	IncrementPatch Increment = "patch"
+)
+
+// nextPreReleases are the prerelease identifiers NextVersion produces, in the order they sort.
+var nextPreReleases = []string{"alpha", "beta", "rc"}
+
+// NextVersionDetails is the version after the base tag, as calculated by NextVersion.
+type NextVersionDetails struct {
+	// Version is the next version, e.g. `3.2.0-rc.2`.
+	Version string
+	// BaseTag is the tag the version was bumped from, or empty if no tag was found.
+	BaseTag string
+	// Tag is the name of the tag for Version, with the same module prefix as BaseTag.
+	Tag string
+	// Commit is the commit being versioned, which Tag would point at.
+	Commit plumbing.Hash
+}
+
+// NextVersion bumps the base version of `opts.Commitish`, found as for GetVersionDetailsWithOptions,
+// to the next release or, if `pre` is set, the next prerelease with that identifier. Prerelease tags
+// are candidates for the base version unless `opts.PreReleaseTagPolicies` says otherwise, so that
+// `3.2.0-rc.1` can be followed by `3.2.0-rc.2`.
+//
+// As with `npm version`, incrementing a component of a prerelease which the prerelease has already
+// incremented releases it, e.g. `--minor` after `3.2.0-rc.1` is `3.2.0`. With a prerelease
+// identifier, the increment is always applied: `--minor --pre alpha` after `3.2.0-rc.1` is
+// `3.3.0-alpha.1`.
+func NextVersion(opts LanguageVersionsOptions, increment Increment, pre string) (*NextVersionDetails, error) {
+	if pre != "" && !contains(nextPreReleases, pre) {
+		return nil, fmt.Errorf("invalid prerelease %q, expected one of %s", pre, strings.Join(nextPreReleases, ", "))
+	}
+	policies := map[string]TagPolicy{"*": TagPolicyRelease}
+	for identifier, policy := range opts.PreReleaseTagPolicies {
+		policies[identifier] = policy
+	}
+	opts.PreReleaseTagPolicies = policies
+
+	opts, commit, err := resolveCommit(opts)
+	if err != nil {
+		return nil, err
+	}
+	baseVersion, baseTag, _, err := determineBaseVersion(opts, &commit.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("error determining base version: %w", err)
+	}
+	base, err := semver.Parse(baseVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing base version %q: %w", baseVersion, err)
+	}
+
+	next, err := bumpVersion(base, increment, pre)
+	if err != nil {
+		return nil, err
+	}
+	opts.explainf("bump: %s to %s", base, next)
+
+	details := &NextVersionDetails{
+		Version: next.String(),
+		Tag:     "v" + next.String(),
+		Commit:  commit.Hash,
+	}
+	if baseTag != nil {
+		details.BaseTag = baseTag.Name().Short()
+		if prefix := ModuleTagPrefix(details.BaseTag); prefix != "" {
+			details.Tag = prefix + "/" + details.Tag
+		}
+	}
+	return details, nil
+}
+
+// bumpVersion returns the version after `base` for the given increment and prerelease identifier.
+func bumpVersion(base semver.Version, increment Increment, pre string) (semver.Version, error) {
+	next := semver.Version{Major: base.Major, Minor: base.Minor, Patch: base.Patch}
+	isPre := len(base.Pre) > 0
+
+	switch increment {
+	case IncrementMajor:
+		if !isPre || pre != "" || base.Minor != 0 || base.Patch != 0 {
+			next.Major++
+			next.Minor = 0
+			next.Patch = 0
+		}
+	case IncrementMinor:
+		if !isPre || pre != "" || base.Patch != 0 {
+			next.Minor++
+			next.Patch = 0
+		}
+	case IncrementPatch:
+		if !isPre || pre != "" {
+			next.Patch++
+		}
+	case IncrementNone:
+		if !isPre && pre == "" {
+			return next, fmt.Errorf("base version %s is already released, so needs --major, --minor or --patch", base)
+		}
+		if !isPre {
+			return next, fmt.Errorf("base version %s is a release, so --pre needs --major, --minor or --patch", base)
+		}
+	default:
+		return next, fmt.Errorf("invalid increment %q", increment)
+	}
+
+	if pre != "" {
+		next.Pre = []semver.PRVersion{{VersionStr: pre}, {VersionNum: 1, IsNum: true}}
+		// The next prerelease of the same release continues from the number of the base version
+		if increment == IncrementNone && base.Pre[0].VersionStr == pre {
+			number := uint64(0)
+			if len(base.Pre) > 1 && base.Pre[1].IsNum {
+				number = base.Pre[1].VersionNum
+			}
+			next.Pre[1].VersionNum = number + 1
+		}
+	}
+
+	if !next.GT(base) {
+		return next, fmt.Errorf("next version %s does not sort after the base version %s", next, base)
+	}
+	return next, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
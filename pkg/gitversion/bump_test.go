@@ -0,0 +1,108 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		base      string
+		increment Increment
+		pre       string
+		expected  string
+	}{
+		{"3.1.0", IncrementMajor, "", "4.0.0"},
+		{"3.1.0", IncrementMinor, "", "3.2.0"},
+		{"3.1.0", IncrementPatch, "", "3.1.1"},
+		{"3.1.0", IncrementMinor, "rc", "3.2.0-rc.1"},
+		{"3.1.0", IncrementPatch, "alpha", "3.1.1-alpha.1"},
+
+		{"3.2.0-rc.1", IncrementNone, "rc", "3.2.0-rc.2"},
+		{"3.2.0-rc", IncrementNone, "rc", "3.2.0-rc.1"},
+		{"3.2.0-alpha.3", IncrementNone, "beta", "3.2.0-beta.1"},
+		{"3.2.0-rc.1", IncrementNone, "", "3.2.0"},
+
+		// Increments already made by the prerelease release it
+		{"3.2.0-rc.1", IncrementMinor, "", "3.2.0"},
+		{"3.2.0-rc.1", IncrementPatch, "", "3.2.0"},
+		{"3.2.0-rc.1", IncrementMajor, "", "4.0.0"},
+		{"4.0.0-rc.1", IncrementMajor, "", "4.0.0"},
+		{"3.2.1-rc.1", IncrementMinor, "", "3.3.0"},
+
+		// With a prerelease identifier the increment is always applied
+		{"3.2.0-rc.1", IncrementMinor, "alpha", "3.3.0-alpha.1"},
+		{"3.2.0-rc.1", IncrementPatch, "rc", "3.2.1-rc.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.base+" "+string(test.increment)+" "+test.pre, func(t *testing.T) {
+			next, err := bumpVersion(semver.MustParse(test.base), test.increment, test.pre)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, next.String())
+		})
+	}
+
+	_, err := bumpVersion(semver.MustParse("3.1.0"), IncrementNone, "")
+	require.EqualError(t, err, "base version 3.1.0 is already released, so needs --major, --minor or --patch")
+	_, err = bumpVersion(semver.MustParse("3.1.0"), IncrementNone, "rc")
+	require.EqualError(t, err, "base version 3.1.0 is a release, so --pre needs --major, --minor or --patch")
+	_, err = bumpVersion(semver.MustParse("3.2.0-rc.1"), IncrementNone, "beta")
+	require.EqualError(t, err, "next version 3.2.0-beta.1 does not sort after the base version 3.2.0-rc.1")
+}
+
+func TestNextVersion(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v3.1.0", "v3.2.0-rc.1"})
+	require.NoError(t, err)
+
+	opts := LanguageVersionsOptions{Repo: repo, Commitish: plumbing.Revision("HEAD")}
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// Prerelease tags are candidates by default, unlike for get version
+	details, err := NextVersion(opts, IncrementNone, "rc")
+	require.NoError(t, err)
+	require.Equal(t, &NextVersionDetails{
+		Version: "3.2.0-rc.2",
+		BaseTag: "v3.2.0-rc.1",
+		Tag:     "v3.2.0-rc.2",
+		Commit:  head.Hash(),
+	}, details)
+
+	// Policies for other identifiers, as parsed from --prerelease-policy, keep rc tags as candidates
+	opts.PreReleaseTagPolicies = map[string]TagPolicy{}
+	details, err = NextVersion(opts, IncrementNone, "rc")
+	require.NoError(t, err)
+	require.Equal(t, "3.2.0-rc.2", details.Version)
+
+	opts.PreReleaseTagPolicies = map[string]TagPolicy{"alpha": TagPolicyNever}
+	details, err = NextVersion(opts, IncrementNone, "rc")
+	require.NoError(t, err)
+	require.Equal(t, "3.2.0-rc.2", details.Version)
+
+	opts.PreReleaseTagPolicies = map[string]TagPolicy{"rc": TagPolicyNever}
+	details, err = NextVersion(opts, IncrementMinor, "")
+	require.NoError(t, err)
+	require.Equal(t, "3.2.0", details.Version)
+	require.Equal(t, "v3.1.0", details.BaseTag)
+
+	_, err = NextVersion(opts, IncrementNone, "preview")
+	require.EqualError(t, err, `invalid prerelease "preview", expected one of alpha, beta, rc`)
+}
+
+func TestNextVersionModuleTag(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"sdk/v1.4.2"})
+	require.NoError(t, err)
+
+	details, err := NextVersion(LanguageVersionsOptions{Repo: repo, Commitish: plumbing.Revision("HEAD")},
+		IncrementPatch, "")
+	require.NoError(t, err)
+	require.Equal(t, "1.4.3", details.Version)
+	require.Equal(t, "sdk/v1.4.3", details.Tag)
+}
@@ -0,0 +1,287 @@
+package gitversion
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// CompareVersions compares the versions `a` and `b` the way the package registry for `language`
+// orders them, returning -1, 0 or 1 if `a` sorts before, the same as or after `b`:
+//
+//   - generic and javascript use semver precedence, as npm does, ignoring build metadata.
+//   - python uses PEP440 ordering, as PyPI does. Generic versions are converted to the Python
+//     version pulumictl would publish before they are compared, keeping any commit hash in the
+//     local version.
+//   - dotnet uses NuGet ordering, which allows a fourth revision number and compares prerelease
+//     labels case-insensitively.
+func CompareVersions(language, a, b string) (int, error) {
+	var compare func(a, b string) (int, error)
+	switch strings.ToLower(language) {
+	case "", "generic", "javascript":
+		compare = compareSemver
+	case "python":
+		compare = comparePython
+	case "dotnet":
+		compare = compareNuGet
+	default:
+		return 0, fmt.Errorf("invalid language %q", language)
+	}
+	return compare(a, b)
+}
+
+func compareSemver(a, b string) (int, error) {
+	va, err := semver.Parse(strings.TrimPrefix(a, "v"))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid semver version: %w", a, err)
+	}
+	vb, err := semver.Parse(strings.TrimPrefix(b, "v"))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid semver version: %w", b, err)
+	}
+	return va.Compare(vb), nil
+}
+
+func comparePython(a, b string) (int, error) {
+	parse := func(version string) (*pep440Version, error) {
+		// Semver versions are converted as for publishing, but keep all of their build metadata, as
+		// PEP440 orders a local version after the public version
+		if parsed, err := semver.Parse(strings.TrimPrefix(version, "v")); err == nil {
+			build := parsed.Build
+			parsed.Build = nil
+			if python, err := pythonVersion(parsed, true); err == nil {
+				version = python + pythonLocal(build)
+			}
+		}
+		v, err := parsePEP440(version)
+		if err != nil {
+			return nil, err
+		}
+		if v.Pre == "d" {
+			return nil, fmt.Errorf("%q is not a valid PEP440 version", version)
+		}
+		return v, nil
+	}
+
+	va, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.compare(vb), nil
+}
+
+// compare orders PEP440 versions by epoch, release, pre-release, post-release, developmental
+// release and local version. See "Summary of permitted suffixes and relative ordering" in PEP440.
+func (v *pep440Version) compare(other *pep440Version) int {
+	if c := compareNumeric(v.Epoch, other.Epoch); c != 0 {
+		return c
+	}
+
+	// Trailing zeros in the release are not significant, so 1.0 == 1.0.0
+	for i := 0; i < len(v.Release) || i < len(other.Release); i++ {
+		var a, b string
+		if i < len(v.Release) {
+			a = v.Release[i]
+		}
+		if i < len(other.Release) {
+			b = other.Release[i]
+		}
+		if c := compareNumeric(a, b); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInts(v.preRank(), other.preRank()); c != 0 {
+		return c
+	}
+	if v.Pre != "" && other.Pre != "" {
+		if c := compareNumeric(v.PreN, other.PreN); c != 0 {
+			return c
+		}
+	}
+
+	// No post-release sorts before any post-release, and no developmental release after any
+	if c := compareOptional(v.HasPost, v.PostN, other.HasPost, other.PostN, -1); c != 0 {
+		return c
+	}
+	if c := compareOptional(v.HasDev, v.DevN, other.HasDev, other.DevN, 1); c != 0 {
+		return c
+	}
+
+	// A local version sorts after the public version. Segments are compared in turn, with
+	// numbers sorting after strings and compared numerically.
+	for i := 0; i < len(v.Local) || i < len(other.Local); i++ {
+		if i >= len(v.Local) {
+			return -1
+		}
+		if i >= len(other.Local) {
+			return 1
+		}
+		a, b := v.Local[i], other.Local[i]
+		switch {
+		case isNumeric(a) && isNumeric(b):
+			if c := compareNumeric(a, b); c != 0 {
+				return c
+			}
+		case isNumeric(a):
+			return 1
+		case isNumeric(b):
+			return -1
+		default:
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// preRank orders the kinds of pre-release: a developmental release of a final release sorts before
+// any pre-release, and a final release after them.
+func (v *pep440Version) preRank() int {
+	switch {
+	case v.Pre == "a":
+		return 1
+	case v.Pre == "b":
+		return 2
+	case v.Pre == "rc":
+		return 3
+	case v.HasDev && !v.HasPost:
+		return 0
+	default:
+		return 4
+	}
+}
+
+// compareOptional compares optional numbers, where a missing number sorts before every number if
+// `missing` is -1, or after every number if it is 1.
+func compareOptional(hasA bool, a string, hasB bool, b string, missing int) int {
+	switch {
+	case hasA && hasB:
+		return compareNumeric(a, b)
+	case hasA:
+		return -missing
+	case hasB:
+		return missing
+	default:
+		return 0
+	}
+}
+
+// compareNumeric compares arbitrarily large decimal numbers, where the empty string is 0.
+func compareNumeric(a, b string) int {
+	var na, nb big.Int
+	na.SetString("0"+a, 10)
+	nb.SetString("0"+b, 10)
+	return na.Cmp(&nb)
+}
+
+// isNumeric returns whether `s` is a non-empty string of decimal digits.
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// nugetVersion is a NuGet package version: a semver version with an optional fourth revision
+// number.
+type nugetVersion struct {
+	numbers [4]string
+	release []string
+}
+
+func parseNuGet(version string) (*nugetVersion, error) {
+	// Build metadata is not part of NuGet ordering
+	version, _, _ = strings.Cut(version, "+")
+	core, release, hasRelease := strings.Cut(version, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) < 2 || len(parts) > 4 {
+		return nil, fmt.Errorf("%q is not a valid NuGet version", version)
+	}
+	v := &nugetVersion{}
+	for i, part := range parts {
+		if !isNumeric(part) {
+			return nil, fmt.Errorf("%q is not a valid NuGet version", version)
+		}
+		v.numbers[i] = part
+	}
+	if hasRelease {
+		v.release = strings.Split(release, ".")
+		for _, label := range v.release {
+			if label == "" {
+				return nil, fmt.Errorf("%q is not a valid NuGet version", version)
+			}
+		}
+	}
+	return v, nil
+}
+
+func compareNuGet(a, b string) (int, error) {
+	va, err := parseNuGet(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseNuGet(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range va.numbers {
+		if c := compareNumeric(va.numbers[i], vb.numbers[i]); c != 0 {
+			return c, nil
+		}
+	}
+
+	// A release sorts after its prereleases
+	switch {
+	case len(va.release) == 0 && len(vb.release) == 0:
+		return 0, nil
+	case len(va.release) == 0:
+		return 1, nil
+	case len(vb.release) == 0:
+		return -1, nil
+	}
+
+	// Labels are compared in turn, numbers numerically and before strings, which are compared
+	// case-insensitively. A prefix of a longer release label sorts before it.
+	for i := 0; i < len(va.release) || i < len(vb.release); i++ {
+		if i >= len(va.release) {
+			return -1, nil
+		}
+		if i >= len(vb.release) {
+			return 1, nil
+		}
+		la, lb := va.release[i], vb.release[i]
+		switch {
+		case isNumeric(la) && isNumeric(lb):
+			if c := compareNumeric(la, lb); c != 0 {
+				return c, nil
+			}
+		case isNumeric(la):
+			return -1, nil
+		case isNumeric(lb):
+			return 1, nil
+		default:
+			if c := strings.Compare(strings.ToLower(la), strings.ToLower(lb)); c != 0 {
+				return c, nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		language string
+		a, b     string
+		expected int
+	}{
+		{"generic", "1.0.0", "v1.0.0", 0},
+		{"generic", "1.0.0-alpha.1", "1.0.0", -1},
+		{"generic", "1.0.0-alpha.10", "1.0.0-alpha.9", 1},
+		{"generic", "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"generic", "1.0.0-beta.1", "1.0.0-Beta.1", 1},
+		{"javascript", "v1.0.0+abcdef12", "v1.0.0+dirty", 0},
+
+		// Timestamped alphas which sort differently on npm and PyPI
+		{"javascript", "3.1.0-alpha.1697040000+dirty", "3.1.0-alpha.1697040000", 0},
+		{"python", "3.1.0a1697040000+dirty", "3.1.0a1697040000", 1},
+		{"javascript", "1.0.0-dev.5", "1.0.0-alpha.1", 1},
+		{"python", "1.0.0-dev.5", "1.0.0-alpha.1", -1},
+		{"javascript", "1.0.0-alpha", "1.0.0-alpha.0", -1},
+		{"python", "1.0.0-alpha", "1.0.0-alpha.0", 0},
+
+		{"python", "1.0", "1.0.0", 0},
+		// A commit hash is kept as the local version, which sorts after the public version
+		{"python", "1.0.0+deadbeef", "1.0.0", 1},
+		{"python", "1.0.0+deadbee", "1.0.0", 1},
+		{"python", "1.0.0-alpha.1+deadbeef.dirty", "1.0.0-alpha.1+deadbeef", 1},
+		{"python", "1.0.0.dev1", "1.0.0a1", -1},
+		{"python", "1.0.0a1.dev1", "1.0.0a1", -1},
+		{"python", "1.0.0a2", "1.0.0b1", -1},
+		{"python", "1.0.0rc1", "1.0.0", -1},
+		{"python", "1.0.0", "1.0.0.post1", -1},
+		{"python", "1.0.0.post1.dev1", "1.0.0.post1", -1},
+		{"python", "1.0.0.post1.dev1", "1.0.0", 1},
+		{"python", "1.3.0a5.post1697040000", "1.3.0a5", 1},
+		{"python", "1.3.0a5.post1697040000", "1.3.0a6", -1},
+		{"python", "1.0.0+abc", "1.0.0+5", -1},
+		{"python", "1.0.0+abc.1", "1.0.0+abc", 1},
+		{"python", "1!0.1", "2.0", 1},
+		{"python", "1.0.0a99999999999999999999", "1.0.0a100000000000000000000", -1},
+
+		{"dotnet", "1.0.0-Beta.1", "1.0.0-beta.1", 0},
+		{"dotnet", "1.0.0.0", "1.0.0", 0},
+		{"dotnet", "1.0.0.1", "1.0.0", 1},
+		{"dotnet", "1.0.0-alpha.1", "1.0.0-alpha.a", -1},
+		{"dotnet", "1.0.0-alpha", "1.0.0", -1},
+		{"dotnet", "1.0.0+build", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		c, err := CompareVersions(tt.language, tt.a, tt.b)
+		require.NoError(t, err, "%s %s %s", tt.language, tt.a, tt.b)
+		require.Equal(t, tt.expected, c, "%s %s %s", tt.language, tt.a, tt.b)
+
+		c, err = CompareVersions(tt.language, tt.b, tt.a)
+		require.NoError(t, err)
+		require.Equal(t, -tt.expected, c, "%s %s %s", tt.language, tt.b, tt.a)
+	}
+
+	_, err := CompareVersions("python", "1.0.0d1", "1.0.0")
+	require.ErrorContains(t, err, "not a valid PEP440 version")
+	_, err = CompareVersions("generic", "1.0", "1.0.0")
+	require.ErrorContains(t, err, "not a valid semver version")
+	_, err = CompareVersions("dotnet", "1.0.0.0.0", "1.0.0")
+	require.ErrorContains(t, err, "not a valid NuGet version")
+	_, err = CompareVersions("go", "v1.0.0", "v1.0.0")
+	require.ErrorContains(t, err, "invalid language")
+}
@@ -0,0 +1,31 @@
+package gitversion
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+)
+
+// checkConstraint returns an error if the version in `components` is outside the semver range in
+// `opts.Satisfies`, such as ">=3.0.0 <4.0.0". Prereleases are checked as the release they lead up
+// to, so that a `3.0.0-alpha` version on a v2 maintenance branch fails "<3.0.0".
+func checkConstraint(opts LanguageVersionsOptions, components *versionComponents) error {
+	if opts.Satisfies == "" {
+		return nil
+	}
+	constraint, err := semver.ParseRange(opts.Satisfies)
+	if err != nil {
+		return fmt.Errorf("invalid version range %q: %w", opts.Satisfies, err)
+	}
+
+	release := semver.Version{
+		Major: components.Semver.Major,
+		Minor: components.Semver.Minor,
+		Patch: components.Semver.Patch,
+	}
+	if !constraint(release) {
+		return fmt.Errorf("version %s does not satisfy %q", release, opts.Satisfies)
+	}
+	opts.explainf("range: %s satisfies %q", release, opts.Satisfies)
+	return nil
+}
@@ -0,0 +1,47 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersionSatisfies(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v2.3.0"})
+	require.NoError(t, err)
+
+	getVersion := func(satisfies, releasePrefix string) (*VersionDetails, error) {
+		return GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:          repo,
+			Commitish:     plumbing.Revision("HEAD"),
+			ReleasePrefix: releasePrefix,
+			Satisfies:     satisfies,
+		})
+	}
+
+	_, err = getVersion(">=2.0.0 <3.0.0", "")
+	require.NoError(t, err)
+	_, err = getVersion("<2.3.0 || >=3.0.0", "")
+	require.EqualError(t, err, `version 2.3.0 does not satisfy "<2.3.0 || >=3.0.0"`)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "after.txt", "after")
+	_, err = workTree.Commit("After the tag", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	details, err := getVersion(">=2.0.0 <3.0.0", "")
+	require.NoError(t, err)
+	require.Contains(t, details.SemVer, "2.4.0-alpha.")
+
+	// A prerelease for the next major version doesn't sneak in under it
+	_, err = getVersion(">=2.0.0 <3.0.0", "3.0.0")
+	require.EqualError(t, err, `version 3.0.0 does not satisfy ">=2.0.0 <3.0.0"`)
+
+	_, err = getVersion("~2", "")
+	require.ErrorContains(t, err, `invalid version range "~2"`)
+}
@@ -0,0 +1,111 @@
+package gitversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// BumpStrategy controls how the next version is derived from the base tag when the commit being
+// versioned is past it.
+type BumpStrategy string
+
+const (
+	// BumpStrategyDefault bumps the minor version, or the patch version for 0.x releases.
+	BumpStrategyDefault BumpStrategy = ""
+	// BumpStrategyConventional reads the Conventional Commits (https://www.conventionalcommits.org)
+	// messages since the base tag: `feat` bumps the minor version, `fix` and anything else bumps the
+	// patch version and breaking changes bump the major version.
+	BumpStrategyConventional BumpStrategy = "conventional"
+)
+
+// ParseBumpStrategy converts a user supplied strategy name into a BumpStrategy.
+func ParseBumpStrategy(name string) (BumpStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", "default":
+		return BumpStrategyDefault, nil
+	case "conventional":
+		return BumpStrategyConventional, nil
+	default:
+		return "", fmt.Errorf("invalid bump strategy %q", name)
+	}
+}
+
+type bumpLevel int
+
+const (
+	bumpPatch bumpLevel = iota
+	bumpMinor
+	bumpMajor
+)
+
+func (l bumpLevel) String() string {
+	switch l {
+	case bumpMajor:
+		return "major"
+	case bumpMinor:
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+// applyBump increments the component of `version` for the given level. While the major version is
+// 0 each level is shifted down by one, so breaking changes bump the minor version and everything
+// else bumps the patch version.
+func applyBump(version *semver.Version, level bumpLevel) {
+	if version.Major == 0 && level > bumpPatch {
+		level--
+	}
+
+	switch level {
+	case bumpMajor:
+		version.Major++
+		version.Minor = 0
+		version.Patch = 0
+	case bumpMinor:
+		version.Minor++
+		version.Patch = 0
+	default:
+		version.Patch++
+	}
+}
+
+var (
+	conventionalHeaderRe   = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:\s`)
+	conventionalBreakingRe = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// conventionalBump returns the bump level implied by a single commit message.
+func conventionalBump(message string) bumpLevel {
+	if conventionalBreakingRe.MatchString(message) {
+		return bumpMajor
+	}
+
+	header := strings.SplitN(message, "\n", 2)[0]
+	matches := conventionalHeaderRe.FindStringSubmatch(header)
+	if matches == nil {
+		return bumpPatch
+	}
+	if matches[3] == "!" {
+		return bumpMajor
+	}
+	if strings.ToLower(matches[1]) == "feat" {
+		return bumpMinor
+	}
+	return bumpPatch
+}
+
+// conventionalBumpLevel returns the highest bump level of the given commits.
+func conventionalBumpLevel(commits []*object.Commit) bumpLevel {
+	level := bumpPatch
+	for _, commit := range commits {
+		if l := conventionalBump(commit.Message); l > level {
+			level = l
+		}
+	}
+	return level
+}
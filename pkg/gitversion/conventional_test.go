@@ -0,0 +1,106 @@
+package gitversion
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConventionalBump(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bumpLevel
+	}{
+		{"fix: correct a typo", bumpPatch},
+		{"chore(deps): bump everything", bumpPatch},
+		{"Not a conventional commit", bumpPatch},
+		{"feat: add a flag", bumpMinor},
+		{"feat(cli): add a flag\n\nSome details", bumpMinor},
+		{"feat!: remove a flag", bumpMajor},
+		{"refactor(api)!: rename everything", bumpMajor},
+		{"fix: something\n\nBREAKING CHANGE: the flag is gone", bumpMajor},
+		{"fix: something\n\nBREAKING-CHANGE: the flag is gone", bumpMajor},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, conventionalBump(tt.message), tt.message)
+	}
+}
+
+func TestApplyBump(t *testing.T) {
+	bump := func(version string, level bumpLevel) string {
+		v := semver.MustParse(version)
+		applyBump(&v, level)
+		return v.String()
+	}
+
+	require.Equal(t, "1.2.4", bump("1.2.3", bumpPatch))
+	require.Equal(t, "1.3.0", bump("1.2.3", bumpMinor))
+	require.Equal(t, "2.0.0", bump("1.2.3", bumpMajor))
+	require.Equal(t, "0.2.4", bump("0.2.3", bumpPatch))
+	require.Equal(t, "0.2.4", bump("0.2.3", bumpMinor))
+	require.Equal(t, "0.3.0", bump("0.2.3", bumpMajor))
+}
+
+func TestGetVersionConventionalBump(t *testing.T) {
+	versionAfter := func(t *testing.T, tag string, messages ...string) string {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+
+		repo, err = testRepoWithTags(repo, []string{tag})
+		require.NoError(t, err)
+
+		for i, message := range messages {
+			addFile(t, workTree, fmt.Sprintf("change-%d.txt", i), message)
+			_, err = workTree.Commit(message, &git.CommitOptions{Author: testSignature})
+			require.NoError(t, err)
+		}
+
+		version, err := GetLanguageVersionsWithOptions(LanguageVersionsOptions{
+			Repo:           repo,
+			Commitish:      plumbing.Revision("HEAD"),
+			OmitCommitHash: true,
+			BumpStrategy:   BumpStrategyConventional,
+		})
+		require.NoError(t, err)
+		return version.SemVer
+	}
+
+	require.Equal(t, "1.0.1-alpha.0", versionAfter(t, "v1.0.0", "fix: a bug", "chore: tidy"))
+	require.Equal(t, "1.1.0-alpha.0", versionAfter(t, "v1.0.0", "fix: a bug", "feat: a feature"))
+	require.Equal(t, "2.0.0-alpha.0", versionAfter(t, "v1.0.0", "feat!: a breaking feature", "fix: a bug"))
+	require.Equal(t, "0.3.0-alpha.0", versionAfter(t, "v0.2.0", "feat!: a breaking feature"))
+
+	t.Run("Commits before the base tag are ignored", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+
+		addFile(t, workTree, "breaking.txt", "breaking")
+		_, err = workTree.Commit("feat!: an old breaking change", &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+
+		repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+		require.NoError(t, err)
+
+		addFile(t, workTree, "fix.txt", "fix")
+		_, err = workTree.Commit("fix: a bug", &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+
+		version, err := GetLanguageVersionsWithOptions(LanguageVersionsOptions{
+			Repo:           repo,
+			Commitish:      plumbing.Revision("HEAD"),
+			OmitCommitHash: true,
+			BumpStrategy:   BumpStrategyConventional,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "1.0.1-alpha.0", version.SemVer)
+	})
+}
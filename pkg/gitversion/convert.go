@@ -4,121 +4,208 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/blang/semver"
 )
 
-func GetLanguageOptionsFromVersion(version string) (*LanguageVersions, error) {
-	// Strip leading "v" if present
-	normalised := version
-	if strings.HasPrefix(version, "v") {
-		normalised = version[1:]
+// GetVersionFromLanguageVersion converts a version for `language`, such as a PyPI or NuGet package
+// version, back into the generic version. It reverses GetLanguageOptionsFromVersion, apart from
+// any commit hash, which Python versions do not keep.
+func GetVersionFromLanguageVersion(language, version string) (string, error) {
+	var generic string
+	switch strings.ToLower(language) {
+	case "generic", "javascript":
+		generic = strings.TrimPrefix(version, "v")
+	case "dotnet":
+		// NuGet allows a fourth part, the revision, which is dropped when it is 0
+		generic = version
+		end := strings.IndexAny(version, "-+")
+		if end < 0 {
+			end = len(version)
+		}
+		if parts := strings.Split(version[:end], "."); len(parts) == 4 {
+			if parts[3] != "0" {
+				return "", fmt.Errorf("%q has a revision, which has no semver equivalent", version)
+			}
+			generic = strings.Join(parts[:3], ".") + version[end:]
+		}
+	case "python":
+		pythonVersion, err := parsePEP440(version)
+		if err != nil {
+			return "", err
+		}
+		if generic, err = pythonVersion.semver(); err != nil {
+			return "", fmt.Errorf("converting %q: %w", version, err)
+		}
+	default:
+		return "", fmt.Errorf("invalid language %q", language)
 	}
 
-	parts := strings.SplitN(normalised, ".", 3)
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("version did not contain exactly 3 parts as expected: %q", version)
+	if _, err := semver.Parse(generic); err != nil {
+		return "", fmt.Errorf("%q is not a valid %s version: %w", version, language, err)
 	}
+	return generic, nil
+}
 
-	major := parts[0]
-	minor := parts[1]
-	patch := parts[2]
+// GetLanguageOptionsFromVersion converts a generic version, with or without a leading "v", into
+// the version for each language. See ConvertVersion.
+func GetLanguageOptionsFromVersion(version string) (*LanguageVersions, error) {
+	return ConvertVersion(version, false)
+}
 
-	pythonPatch, err := convertPatchToPython(patch)
+// ConvertVersion converts a generic version, with or without a leading "v", into the version for
+// each language. Python versions are normalised PEP440 (https://peps.python.org/pep-0440/):
+//
+//   - The prerelease identifiers alpha, beta, rc and dev become a, b, rc and .dev, followed by the
+//     first number of the prerelease, or 0 if there is none. A second number becomes a
+//     post-release, as PEP440 only allows one pre-release number, so `1.3.0-alpha.5.1697040000`
+//     becomes `1.3.0a5.post1697040000`.
+//   - Build metadata becomes the local version, apart from the commit hash which gitversion puts
+//     first, so `1.3.0-alpha.5+9fa804e8.dirty` becomes `1.3.0a5+dirty`.
+//
+// Unless `strict` is set, versions which are not valid semver, such as `1.2`, are parsed
+// leniently, identifiers with a number attached, such as `alpha1`, are split, further prerelease
+// identifiers are ignored, and prereleases with an unknown identifier are left out of the Python
+// version. With `strict` these are errors.
+func ConvertVersion(version string, strict bool) (*LanguageVersions, error) {
+	trimmed := strings.TrimPrefix(version, "v")
+	parsed, err := semver.Parse(trimmed)
 	if err != nil {
-		return nil, err
+		if strict {
+			return nil, fmt.Errorf("%q is not a valid semver version: %w", version, err)
+		}
+		if parsed, err = semver.ParseTolerant(trimmed); err != nil {
+			return nil, fmt.Errorf("%q is not a valid version: %w", version, err)
+		}
 	}
 
-	genericVersion := fmt.Sprintf("%s.%s.%s", major, minor, patch)
-	pythonVersion := fmt.Sprintf("%s.%s.%s", major, minor, pythonPatch)
-	jsVersion := fmt.Sprintf("v%s", genericVersion)
-	dotnetVersion := genericVersion
+	python, err := pythonVersion(parsed, strict)
+	if err != nil {
+		return nil, fmt.Errorf("converting %q to a Python version: %w", version, err)
+	}
 
+	generic := parsed.String()
 	return &LanguageVersions{
-		SemVer:     genericVersion,
-		Python:     pythonVersion,
-		JavaScript: jsVersion,
-		DotNet:     dotnetVersion,
+		SemVer:     generic,
+		Python:     python,
+		JavaScript: "v" + generic,
+		DotNet:     generic,
+		Java:       javaVersion(generic),
 	}, nil
 }
 
-func convertPatchToPython(patch string) (string, error) {
-	re := regexp.MustCompile(`^(\d+)(.*)$`)
-	matches := re.FindStringSubmatch(patch)
-	if len(matches) != 3 {
-		return patch, nil
-	}
-	version := matches[1]
-	pre := matches[2]
-	pythonPre, err := getPythonPreVersion(pre)
-	return version + pythonPre, err
-}
+var (
+	pythonPreLabels = map[string]string{"dev": ".dev", "alpha": "a", "beta": "b", "rc": "rc"}
 
-func getPythonPreVersion(preVersion string) (string, error) {
-	if preVersion == "" {
-		return preVersion, nil
-	}
+	attachedNumberRe = regexp.MustCompile(`^([A-Za-z]+)(\d+)$`)
+	commitHashRe     = regexp.MustCompile(`^[0-9a-f]{8}$`)
+)
+
+// pythonVersion converts `version` into a normalised PEP440 version, as described by ConvertVersion.
+func pythonVersion(version semver.Version, strict bool) (string, error) {
+	python := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
 
-	prefix, remaining := getPythonPrePrefix(preVersion)
+	pre, err := pythonPreRelease(version.Pre, strict)
+	if err != nil {
+		return "", err
+	}
+	python += pre
 
-	isDirty := strings.Contains(preVersion, "dirty")
-	if isDirty {
-		remaining = strings.Replace(remaining, "dirty", "", 1)
+	build := version.Build
+	if len(build) > 0 && commitHashRe.MatchString(build[0]) {
+		build = build[1:]
 	}
+	return python + pythonLocal(build), nil
+}
 
-	// Find the hash and remove to make sure we don't pick up the hash as a number
-	hashRe := regexp.MustCompile(`\+[0-9a-f]{8}\b`)
-	hashMatches := hashRe.FindAllString(remaining, 5)
-	if len(hashMatches) > 0 {
-		// Use the last match in case the build number is also exactly 8 digits long.
-		shortHash := hashMatches[len(hashMatches)-1]
-		remaining = strings.Replace(remaining, shortHash, "", 1)
+// pythonLocal converts semver build metadata into the local version segment of a PEP440 version,
+// including its "+", or "" if there is no build metadata.
+func pythonLocal(build []string) string {
+	if len(build) == 0 {
+		return ""
 	}
-	// Find a number in the middle of non-words (- or .)
-	numRe := regexp.MustCompile(`\W(\d+)(\W|$)`)
-	nums := numRe.FindStringSubmatch(remaining)
-	num := ""
-	// Our match group is in the 2nd array entry.
-	if len(nums) == 3 {
-		num = nums[1]
+	local := make([]string, len(build))
+	for i, identifier := range build {
+		local[i] = strings.ToLower(strings.ReplaceAll(identifier, "-", "."))
 	}
+	return "+" + strings.Join(local, ".")
+}
 
-	// Python uses PEP440, but Pypi has some curiosities.
-	pythonPreVersion := ""
-
-	// PEP440 (https://peps.python.org/pep-0440/) says pre-release parts MUST have a number in them,
-	// but we want to support tags like `v1.0.0-alpha`. If no number is present add `0` to keep PEP440
-	// happy.
-	var pythonPreSuffix string
-	if num == "" {
-		pythonPreSuffix = "0"
-	} else {
-		// Trim the initial "."
-		pythonPreSuffix = num
+// pythonPreRelease converts the semver prerelease identifiers into the pre-release or
+// developmental release segment of a PEP440 version.
+func pythonPreRelease(pre []semver.PRVersion, strict bool) (string, error) {
+	if len(pre) == 0 {
+		return "", nil
 	}
 
-	if prefix != "" {
-		pythonPreVersion = fmt.Sprintf("%s%s", prefix, pythonPreSuffix)
+	identifiers := append([]semver.PRVersion{}, pre...)
+	if identifiers[0].IsNum {
+		if strict {
+			return "", fmt.Errorf("prerelease %q does not start with an identifier", prereleaseString(pre))
+		}
+		return "", nil
+	}
+	if match := attachedNumberRe.FindStringSubmatch(identifiers[0].VersionStr); match != nil {
+		if strict {
+			return "", fmt.Errorf("prerelease %q has no separator between %q and %s",
+				prereleaseString(pre), match[1], match[2])
+		}
+		number, err := semver.NewPRVersion(match[2])
+		if err != nil {
+			return "", err
+		}
+		identifiers = append([]semver.PRVersion{{VersionStr: match[1]}, number}, identifiers[1:]...)
 	}
 
-	// Detect if the git worktree is dirty, and add `dirty` to the version if it is
-	if isDirty {
-		pythonPreVersion = fmt.Sprintf("%s+dirty", pythonPreVersion)
+	label, ok := pythonPreLabels[identifiers[0].VersionStr]
+	if !ok {
+		if strict {
+			return "", fmt.Errorf("prerelease %q is not one of dev, alpha, beta or rc", identifiers[0].VersionStr)
+		}
+		return "", nil
 	}
 
-	return pythonPreVersion, nil
-}
+	var numbers []uint64
+	for _, identifier := range identifiers[1:] {
+		if !identifier.IsNum {
+			if strict {
+				return "", fmt.Errorf("prerelease %q has non-numeric identifier %q", prereleaseString(pre),
+					identifier.VersionStr)
+			}
+			continue
+		}
+		numbers = append(numbers, identifier.VersionNum)
+	}
 
-func getPythonPrePrefix(preVersion string) (string, string) {
-	if strings.HasPrefix(preVersion, "-dev") {
-		return "d", preVersion[4:]
+	maxNumbers := 2
+	if label == ".dev" {
+		// PEP440 has no post-release of a developmental release
+		maxNumbers = 1
+	}
+	if len(numbers) > maxNumbers {
+		if strict {
+			return "", fmt.Errorf("prerelease %q has more numbers than PEP440 allows", prereleaseString(pre))
+		}
+		numbers = numbers[:maxNumbers]
 	}
-	if strings.HasPrefix(preVersion, "-alpha") {
-		return "a", preVersion[6:]
+
+	// PEP440 says pre-release parts MUST have a number in them, but we want to support tags like
+	// `v1.0.0-alpha`. If no number is present add `0` to keep PEP440 happy.
+	if len(numbers) == 0 {
+		numbers = []uint64{0}
 	}
-	if strings.HasPrefix(preVersion, "-beta") {
-		return "b", preVersion[5:]
+	result := fmt.Sprintf("%s%d", label, numbers[0])
+	if len(numbers) > 1 {
+		result += fmt.Sprintf(".post%d", numbers[1])
 	}
-	if strings.HasPrefix(preVersion, "-rc") {
-		return "rc", preVersion[3:]
+	return result, nil
+}
+
+// prereleaseString joins the prerelease identifiers of a semver version.
+func prereleaseString(pre []semver.PRVersion) string {
+	parts := make([]string, len(pre))
+	for i, identifier := range pre {
+		parts[i] = identifier.String()
 	}
-	return "", ""
+	return strings.Join(parts, ".")
 }
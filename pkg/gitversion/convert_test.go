@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,89 +14,90 @@ type versionTest struct {
 	python string
 }
 
+var conversionTests = []versionTest{
+	{
+		semver: "0.0.0",
+		python: "0.0.0",
+	},
+	{
+		desc:   "Repo with no tags",
+		semver: "0.0.1-alpha.0+68804cfa",
+		python: "0.0.1a0",
+	},
+	{
+		desc:   "Repo with exact tag",
+		semver: "1.0.0",
+		python: "1.0.0",
+	},
+	{
+		desc:   "Repo with with commit after tag",
+		semver: "1.1.0-alpha.0+9fa804e8",
+		python: "1.1.0a0",
+	},
+	{
+		desc:   "Repo with with commit after tag and dirty",
+		semver: "1.1.0-alpha.0+9fa804e8.dirty",
+		python: "1.1.0a0+dirty",
+	},
+	{
+		desc:   "Repo with no tags and dirty",
+		semver: "0.0.1-alpha.0+68804cfa.dirty",
+		python: "0.0.1a0+dirty",
+	},
+	{
+		desc:   "Repo with alpha tag and dirty",
+		semver: "1.0.0-alpha.1+e624a7d7.dirty",
+		python: "1.0.0a1+dirty",
+	},
+	{
+		desc:   "Repo with exact tag and dirty",
+		semver: "1.0.0+dirty",
+		python: "1.0.0+dirty",
+	},
+	{
+		desc:   "Repo with un-dotted alpha tag",
+		semver: "1.0.0-alpha+26d1c29c",
+		python: "1.0.0a0",
+	},
+	{
+		desc:   "Repo with un-dotted alpha tag marked for pre-release",
+		semver: "1.0.0-alpha",
+		python: "1.0.0a0",
+	},
+	{
+		desc:   "Repo with dotted alpha tag marked for pre-release",
+		semver: "1.0.0-alpha.1",
+		python: "1.0.0a1",
+	},
+	{
+		desc:   "Repo with beta tag and dirty",
+		semver: "1.0.0-beta.1+e624a7d7.dirty",
+		python: "1.0.0b1+dirty",
+	},
+	{
+		desc:   "Repo with rc tag and dirty",
+		semver: "1.0.0-rc.1+e624a7d7.dirty",
+		python: "1.0.0rc1+dirty",
+	},
+	{
+		desc:   "Repo with dev tag and dirty",
+		semver: "1.0.0-dev.1+e624a7d7.dirty",
+		python: "1.0.0.dev1+dirty",
+	},
+	{
+		desc:   "Master prerelease",
+		semver: "1.93.1-alpha.1675198718+c586f7b1",
+		python: "1.93.1a1675198718",
+	},
+	{
+		desc:   "Master prerelease dirty",
+		semver: "1.93.1-alpha.1675198718+c586f7b1.dirty",
+		python: "1.93.1a1675198718+dirty",
+	},
+}
+
 func TestConversions(t *testing.T) {
-	inputs := []versionTest{
-		{
-			semver: "0.0.0",
-			python: "0.0.0",
-		},
-		{
-			desc:   "Repo with no tags",
-			semver: "0.0.1-alpha.0+68804cfa",
-			python: "0.0.1a0",
-		},
-		{
-			desc:   "Repo with exact tag",
-			semver: "1.0.0",
-			python: "1.0.0",
-		},
-		{
-			desc:   "Repo with with commit after tag",
-			semver: "1.1.0-alpha.0+9fa804e8",
-			python: "1.1.0a0",
-		},
-		{
-			desc:   "Repo with with commit after tag and dirty",
-			semver: "1.1.0-alpha.0+9fa804e8.dirty",
-			python: "1.1.0a0+dirty",
-		},
-		{
-			desc:   "Repo with no tags and dirty",
-			semver: "0.0.1-alpha.0+68804cfa.dirty",
-			python: "0.0.1a0+dirty",
-		},
-		{
-			desc:   "Repo with alpha tag and dirty",
-			semver: "1.0.0-alpha.1+e624a7d7.dirty",
-			python: "1.0.0a1+dirty",
-		},
-		{
-			desc:   "Repo with exact tag and dirty",
-			semver: "1.0.0+dirty",
-			python: "1.0.0+dirty",
-		},
-		{
-			desc:   "Repo with un-dotted alpha tag",
-			semver: "1.0.0-alpha+26d1c29c",
-			python: "1.0.0a0",
-		},
-		{
-			desc:   "Repo with un-dotted alpha tag marked for pre-release",
-			semver: "1.0.0-alpha",
-			python: "1.0.0a0",
-		},
-		{
-			desc:   "Repo with dotted alpha tag marked for pre-release",
-			semver: "1.0.0-alpha.1",
-			python: "1.0.0a1",
-		},
-		{
-			desc:   "Repo with beta tag and dirty",
-			semver: "1.0.0-beta.1+e624a7d7.dirty",
-			python: "1.0.0b1+dirty",
-		},
-		{
-			desc:   "Repo with rc tag and dirty",
-			semver: "1.0.0-rc.1+e624a7d7.dirty",
-			python: "1.0.0rc1+dirty",
-		},
-		{
-			desc:   "Repo with dev tag and dirty",
-			semver: "1.0.0-dev.1+e624a7d7.dirty",
-			python: "1.0.0d1+dirty",
-		},
-		{
-			desc:   "Master prerelease",
-			semver: "1.93.1-alpha.1675198718+c586f7b1",
-			python: "1.93.1a1675198718",
-		},
-		{
-			desc:   "Master prerelease dirty",
-			semver: "1.93.1-alpha.1675198718+c586f7b1.dirty",
-			python: "1.93.1a1675198718+dirty",
-		},
-	}
-	for _, v := range inputs {
+	for _, v := range conversionTests {
 		javascript := "v" + v.semver
 		desc := v.desc
 		if desc == "" {
@@ -119,3 +121,162 @@ func TestConversions(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseConversions(t *testing.T) {
+	for _, v := range conversionTests {
+		desc := v.desc
+		if desc == "" {
+			desc = "test"
+		}
+		t.Run(fmt.Sprintf("%s %s", desc, v.semver), func(t *testing.T) {
+			for _, language := range []string{"generic", "dotnet", "javascript"} {
+				versions, err := GetLanguageOptionsFromVersion(v.semver)
+				require.NoError(t, err)
+				languageVersion := map[string]string{
+					"generic":    versions.SemVer,
+					"dotnet":     versions.DotNet,
+					"javascript": versions.JavaScript,
+				}[language]
+
+				generic, err := GetVersionFromLanguageVersion(language, languageVersion)
+				require.NoError(t, err)
+				require.Equal(t, v.semver, generic, language)
+			}
+
+			// Python versions don't keep the commit hash, or an empty pre-release number, so only the
+			// Python version round-trips exactly.
+			generic, err := GetVersionFromLanguageVersion("python", v.python)
+			require.NoError(t, err)
+			versions, err := GetLanguageOptionsFromVersion(generic)
+			require.NoError(t, err)
+			require.Equal(t, v.python, versions.Python)
+		})
+	}
+}
+
+func TestPythonToSemver(t *testing.T) {
+	tests := []struct {
+		python string
+		semver string
+		err    string
+	}{
+		{python: "3.1.0a1697040000+dirty", semver: "3.1.0-alpha.1697040000+dirty"},
+		{python: "1.93.1a1675198718", semver: "1.93.1-alpha.1675198718"},
+		{python: "1.3.0a5.post1697040000", semver: "1.3.0-alpha.5.1697040000"},
+		{python: "1.0.0d1", semver: "1.0.0-dev.1"},
+		{python: "1.0.0.dev3", semver: "1.0.0-dev.3"},
+		{python: "1.0.0rc1", semver: "1.0.0-rc.1"},
+		{python: "1.0.0c1", semver: "1.0.0-rc.1"},
+		{python: "1.0.0-Beta-02", semver: "1.0.0-beta.2"},
+		{python: "1.0.0.alpha", semver: "1.0.0-alpha.0"},
+		{python: "v2.1", semver: "2.1.0"},
+		{python: "0!2.1.0", semver: "2.1.0"},
+		{python: "1.0.0+ubuntu-1", semver: "1.0.0+ubuntu.1"},
+		{python: "1!2.1.0", err: "version epochs have no semver equivalent"},
+		{python: "1.0.0.post1", err: "post-releases have no semver equivalent"},
+		{python: "1.0.0a1.dev1", err: "developmental releases of pre-releases have no semver equivalent"},
+		{python: "1.0.0.1", err: "more than three parts"},
+		{python: "1.0.0-alpha.1", semver: "1.0.0-alpha.1"},
+		{python: "1.0.0-foo", err: "not a valid PEP440 version"},
+	}
+
+	for _, tt := range tests {
+		generic, err := GetVersionFromLanguageVersion("python", tt.python)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err, tt.python)
+			continue
+		}
+		require.NoError(t, err, tt.python)
+		require.Equal(t, tt.semver, generic, tt.python)
+	}
+}
+
+func TestDotNetToSemver(t *testing.T) {
+	generic, err := GetVersionFromLanguageVersion("dotnet", "1.2.3.0-beta.1")
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3-beta.1", generic)
+
+	_, err = GetVersionFromLanguageVersion("dotnet", "1.2.3.4")
+	require.ErrorContains(t, err, "has a revision")
+
+	_, err = GetVersionFromLanguageVersion("dotnet", "1.2")
+	require.ErrorContains(t, err, "not a valid dotnet version")
+}
+
+func TestConvertVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		semver  string
+		python  string
+		// strictErr is the error with strict conversion, which otherwise gives the same versions.
+		strictErr string
+	}{
+		{version: "1.2.3-alpha.1+build.5", semver: "1.2.3-alpha.1+build.5", python: "1.2.3a1+build.5"},
+		{version: "1.2.3-rc.1.2", semver: "1.2.3-rc.1.2", python: "1.2.3rc1.post2"},
+		{version: "1.3.0-alpha.5.1697040000+9fa804e8", semver: "1.3.0-alpha.5.1697040000+9fa804e8",
+			python: "1.3.0a5.post1697040000"},
+		{version: "1.2.3+Build-5", semver: "1.2.3+Build-5", python: "1.2.3+build.5"},
+		{version: "1.2.3+build.12345678", semver: "1.2.3+build.12345678", python: "1.2.3+build.12345678"},
+		{version: "1.2.3-dev.4", semver: "1.2.3-dev.4", python: "1.2.3.dev4"},
+		{version: "1.2", semver: "1.2.0", python: "1.2.0", strictErr: `"1.2" is not a valid semver version`},
+		{version: "1.2.3-alpha1", semver: "1.2.3-alpha1", python: "1.2.3a1",
+			strictErr: `prerelease "alpha1" has no separator between "alpha" and 1`},
+		{version: "1.2.3-preview.1", semver: "1.2.3-preview.1", python: "1.2.3",
+			strictErr: `prerelease "preview" is not one of dev, alpha, beta or rc`},
+		{version: "1.2.3-1", semver: "1.2.3-1", python: "1.2.3",
+			strictErr: `prerelease "1" does not start with an identifier`},
+		{version: "1.2.3-alpha.x.1", semver: "1.2.3-alpha.x.1", python: "1.2.3a1",
+			strictErr: `prerelease "alpha.x.1" has non-numeric identifier "x"`},
+		{version: "1.2.3-rc.1.2.3", semver: "1.2.3-rc.1.2.3", python: "1.2.3rc1.post2",
+			strictErr: `prerelease "rc.1.2.3" has more numbers than PEP440 allows`},
+		{version: "1.2.3-dev.1.2", semver: "1.2.3-dev.1.2", python: "1.2.3.dev1",
+			strictErr: `prerelease "dev.1.2" has more numbers than PEP440 allows`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			versions, err := ConvertVersion(tt.version, false)
+			require.NoError(t, err)
+			require.Equal(t, tt.semver, versions.SemVer)
+			require.Equal(t, tt.python, versions.Python)
+
+			versions, err = ConvertVersion(tt.version, true)
+			if tt.strictErr != "" {
+				require.ErrorContains(t, err, tt.strictErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.python, versions.Python)
+		})
+	}
+
+	_, err := ConvertVersion("one.two", false)
+	require.ErrorContains(t, err, `"one.two" is not a valid version`)
+}
+
+func TestGetVersionPythonMatchesConvertVersion(t *testing.T) {
+	for tag, python := range map[string]string{
+		"v2.0.0-dev.1":   "2.0.0.dev1",
+		"v2.0.0-alpha.3": "2.0.0a3",
+		"v2.0.0-alpha":   "2.0.0a0",
+	} {
+		tag, python := tag, python
+		t.Run(tag, func(t *testing.T) {
+			repo, err := testRepoCreate()
+			require.NoError(t, err)
+			repo, err = testRepoWithTags(repo, []string{tag})
+			require.NoError(t, err)
+
+			details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+				Repo:      repo,
+				Commitish: plumbing.Revision("HEAD"),
+			})
+			require.NoError(t, err)
+			require.Equal(t, python, details.Python)
+
+			converted, err := ConvertVersion(details.SemVer, true)
+			require.NoError(t, err)
+			require.Equal(t, converted.Python, details.Python)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package gitversion
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	dstar "github.com/bmatcuk/doublestar"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// dirtyFilter selects the files which are considered when deciding whether the work tree is dirty.
+type dirtyFilter struct {
+	paths pathFilter
+	// ignore holds doublestar glob patterns, e.g. "bin/**", for files whose changes are ignored.
+	ignore []string
+}
+
+// newDirtyFilter validates the `ignore` glob patterns and combines them with `paths`.
+func newDirtyFilter(paths []string, ignore []string) (dirtyFilter, error) {
+	for _, pattern := range ignore {
+		if err := checkGlob(pattern); err != nil {
+			return dirtyFilter{}, fmt.Errorf("invalid dirty ignore pattern %q: %w", pattern, err)
+		}
+	}
+	return dirtyFilter{paths: newPathFilter(paths), ignore: ignore}, nil
+}
+
+// matches returns whether changes to the file `name` make the work tree dirty. A file is ignored if
+// an ignore pattern matches it or any of its parent directories, so "bin" ignores everything under
+// bin/.
+func (f dirtyFilter) matches(name string) bool {
+	if !f.paths.matches(name) {
+		return false
+	}
+	for _, pattern := range f.ignore {
+		pattern = strings.TrimSuffix(pattern, "/")
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			// Patterns were validated by newDirtyFilter, so matching cannot fail
+			if matched, _ := dstar.Match(pattern, p); matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dirtyFilesForOptions returns the modified files in the work tree of `opts.Repo` which are under
+// `opts.Paths` and not ignored by `opts.DirtyIgnore`.
+func dirtyFilesForOptions(opts LanguageVersionsOptions) ([]string, error) {
+	filter, err := newDirtyFilter(opts.Paths, opts.DirtyIgnore)
+	if err != nil {
+		return nil, err
+	}
+	return workTreeDirtyFiles(opts.Repo, filter)
+}
+
+// workTreeDirtyFiles returns the tracked files in the worktree associated with the given repository
+// which have local modifications, and are matched by `filter`. The result is sorted.
+//
+// For repositories on disk the index is compared with the files directly, rather than through
+// Worktree.Status which is really slow on larger repositories. Only files whose size or
+// modification time differ from the index are read, and changes to whitespace at the end of lines
+// are ignored, as with `git diff-files --ignore-space-at-eol`.
+func workTreeDirtyFiles(repo *git.Repository, filter dirtyFilter) ([]string, error) {
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("looking up worktree: %w", err)
+	}
+
+	var dirty []string
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		status, err := workTree.Status()
+		if err != nil {
+			return nil, fmt.Errorf("error getting git worktree status: %w", err)
+		}
+		for name, fileStatus := range status {
+			if filter.matches(name) &&
+				(fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified) {
+				dirty = append(dirty, name)
+			}
+		}
+	} else {
+		idx, err := repo.Storer.Index()
+		if err != nil {
+			return nil, fmt.Errorf("reading index: %w", err)
+		}
+
+		// Files modified in the same second the index was written may have changed without their
+		// size or modification time changing, so they are always read. See racy-git.txt in the git docs.
+		var indexTime time.Time
+		if stat, err := os.Stat(filepath.Join(storage.Filesystem().Root(), "index")); err == nil {
+			indexTime = stat.ModTime()
+		}
+
+		root := workTree.Filesystem.Root()
+		for _, entry := range idx.Entries {
+			if entry.SkipWorktree || !filter.matches(entry.Name) {
+				continue
+			}
+			modified, err := entryModified(repo, root, entry, indexTime)
+			if err != nil {
+				return nil, fmt.Errorf("checking %q: %w", entry.Name, err)
+			}
+			if modified && (len(dirty) == 0 || dirty[len(dirty)-1] != entry.Name) {
+				dirty = append(dirty, entry.Name)
+			}
+		}
+	}
+
+	sort.Strings(dirty)
+	return dirty, nil
+}
+
+// entryModified returns whether the file for the index `entry` in the work tree at `root` differs
+// from the index.
+func entryModified(repo *git.Repository, root string, entry *index.Entry, indexTime time.Time) (bool, error) {
+	// Entries for files with merge conflicts have a non-zero stage
+	if entry.Stage != 0 || entry.IntentToAdd {
+		return true, nil
+	}
+	if entry.Mode == filemode.Submodule {
+		return false, nil
+	}
+
+	fileName := filepath.Join(root, filepath.FromSlash(entry.Name))
+	stat, err := os.Lstat(fileName)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	mode, err := filemode.NewFromOSFileMode(stat.Mode())
+	if err != nil || !sameMode(entry.Mode, mode) {
+		return true, nil
+	}
+
+	modTime := stat.ModTime()
+	sameTime := entry.ModifiedAt.Equal(modTime) ||
+		entry.ModifiedAt.Nanosecond() == 0 && entry.ModifiedAt.Unix() == modTime.Unix()
+	racy := !indexTime.IsZero() && modTime.Unix() >= indexTime.Unix()
+	if sameTime && !racy && entry.Size == uint32(stat.Size()) {
+		return false, nil
+	}
+
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := os.Readlink(fileName)
+		if err != nil {
+			return false, err
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else if content, err = os.ReadFile(fileName); err != nil {
+		return false, err
+	}
+	if plumbing.ComputeHash(plumbing.BlobObject, content) == entry.Hash {
+		return false, nil
+	}
+
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return false, fmt.Errorf("reading indexed blob: %w", err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	var indexed bytes.Buffer
+	if _, err := indexed.ReadFrom(reader); err != nil {
+		return false, err
+	}
+	return !equalIgnoringSpaceAtEOL(indexed.Bytes(), content), nil
+}
+
+// sameMode returns whether a file with `mode` in the work tree matches the `indexed` mode. The
+// executable bit is not tracked on Windows.
+func sameMode(indexed, mode filemode.FileMode) bool {
+	if runtime.GOOS == "windows" && mode == filemode.Regular && indexed == filemode.Executable {
+		return true
+	}
+	if indexed == filemode.Deprecated {
+		indexed = filemode.Regular
+	}
+	return indexed == mode
+}
+
+// equalIgnoringSpaceAtEOL returns whether `a` and `b` have the same lines once trailing whitespace,
+// including carriage returns, is removed from each.
+func equalIgnoringSpaceAtEOL(a, b []byte) bool {
+	linesA := bytes.Split(a, []byte("\n"))
+	linesB := bytes.Split(b, []byte("\n"))
+	if len(linesA) != len(linesB) {
+		return false
+	}
+	for i := range linesA {
+		if !bytes.Equal(bytes.TrimRight(linesA[i], " \t\r\f\v"), bytes.TrimRight(linesB[i], " \t\r\f\v")) {
+			return false
+		}
+	}
+	return true
+}
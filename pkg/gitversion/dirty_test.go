@@ -0,0 +1,108 @@
+package gitversion
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirtyFilter(t *testing.T) {
+	_, err := newDirtyFilter(nil, []string{"bin/[a"})
+	require.Error(t, err)
+
+	filter, err := newDirtyFilter([]string{"provider"}, []string{"bin", "**/schema-embed.json", "sdk/**"})
+	require.NoError(t, err)
+	require.True(t, filter.matches("provider/main.go"))
+	require.False(t, filter.matches("provider/cmd/schema-embed.json"))
+	require.False(t, filter.matches("main.go"))
+
+	filter, err = newDirtyFilter(nil, []string{"bin/", "**/schema-embed.json", "sdk/**"})
+	require.NoError(t, err)
+	require.True(t, filter.matches("main.go"))
+	require.True(t, filter.matches("binary/main.go"))
+	require.False(t, filter.matches("bin/provider"))
+	require.False(t, filter.matches("bin/nested/provider"))
+	require.False(t, filter.matches("schema-embed.json"))
+	require.False(t, filter.matches("provider/cmd/schema-embed.json"))
+	require.False(t, filter.matches("sdk/go/main.go"))
+}
+
+func TestEqualIgnoringSpaceAtEOL(t *testing.T) {
+	require.True(t, equalIgnoringSpaceAtEOL([]byte("a\nb\n"), []byte("a\r\nb  \r\n")))
+	require.True(t, equalIgnoringSpaceAtEOL([]byte("a\t\nb"), []byte("a\nb")))
+	require.False(t, equalIgnoringSpaceAtEOL([]byte("a\nb"), []byte("a\nb\n")))
+	require.False(t, equalIgnoringSpaceAtEOL([]byte("a b\n"), []byte("ab\n")))
+}
+
+func TestWorkTreeDirtyFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := testRepoFSCreate(dir)
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	addFile(t, workTree, "main.go", "package main\n")
+	addFile(t, workTree, "bin/provider", "binary")
+	addFile(t, workTree, "script.sh", "#!/bin/sh\n")
+	_, err = workTree.Commit("Initial commit", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	dirtyFiles := func(t *testing.T, ignore ...string) []string {
+		filter, err := newDirtyFilter(nil, ignore)
+		require.NoError(t, err)
+		files, err := workTreeDirtyFiles(repo, filter)
+		require.NoError(t, err)
+		return files
+	}
+	write := func(t *testing.T, name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.Empty(t, dirtyFiles(t))
+
+	t.Run("Touching a file is not a change", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "main.go"), later, later))
+		require.Empty(t, dirtyFiles(t))
+	})
+
+	t.Run("Whitespace at the end of lines is ignored", func(t *testing.T) {
+		write(t, "main.go", "package main  \r\n")
+		require.Empty(t, dirtyFiles(t))
+	})
+
+	t.Run("Changes are reported", func(t *testing.T) {
+		write(t, "main.go", "package other\n")
+		write(t, "bin/provider", "rebuilt")
+		require.NoError(t, os.Chmod(filepath.Join(dir, "script.sh"), 0o700)) //nolint:gosec
+		require.Equal(t, []string{"bin/provider", "main.go", "script.sh"}, dirtyFiles(t))
+		require.Equal(t, []string{"main.go", "script.sh"}, dirtyFiles(t, "bin"))
+		require.Equal(t, []string{"script.sh"}, dirtyFiles(t, "bin/**", "*.go"))
+	})
+
+	t.Run("Deleted files are reported", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "main.go")))
+		require.Contains(t, dirtyFiles(t), "main.go")
+	})
+
+	t.Run("Matches git diff-files", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+
+		c := exec.Command("git", "update-index", "-q", "--refresh")
+		c.Dir = dir
+		_ = c.Run()
+		c = exec.Command("git", "diff-files", "--name-only", "--ignore-space-at-eol")
+		c.Dir = dir
+		output, err := c.Output()
+		require.NoError(t, err)
+		require.Equal(t, strings.Fields(string(output)), dirtyFiles(t))
+	})
+}
@@ -2,20 +2,17 @@ package gitversion
 
 import (
 	"fmt"
-	"os/exec"
+	"io"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	viperlib "github.com/spf13/viper"
-
 	"github.com/blang/semver"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/storer"
-	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 // LanguageVersions contains a generic semantic version and Python-specific version number.
@@ -24,6 +21,22 @@ type LanguageVersions struct {
 	Python     string
 	JavaScript string
 	DotNet     string
+	Java       string
+}
+
+// VersionDetails contains the language-specific versions for a commit along with the repository
+// state they were derived from.
+type VersionDetails struct {
+	LanguageVersions
+
+	// BaseTag is the tag the version was derived from, or empty if no tag was found.
+	BaseTag   string
+	IsExact   bool
+	Dirty     bool
+	ShortHash string
+	Timestamp time.Time
+	// DirtyFiles lists the modified files which made the work tree dirty.
+	DirtyFiles []string
 }
 
 type LanguageVersionsOptions struct {
@@ -33,24 +46,116 @@ type LanguageVersionsOptions struct {
 	ReleasePrefix  string
 	IsPreRelease   bool
 	TagFilter      func(string) bool
+	// PreReleaseTagPolicies controls whether tags with a prerelease identifier, e.g. "beta", are
+	// candidates for the base tag. Identifiers without a policy, either here or under the "*"
+	// identifier, use DefaultPreReleaseTagPolicies.
+	PreReleaseTagPolicies map[string]TagPolicy
+	BumpStrategy          BumpStrategy
+	BaseStrategy          BaseStrategy
+	// FirstParent only follows the first parent of merge commits when looking for the base tag.
+	FirstParent      bool
+	PreReleaseNumber PreReleaseNumber
+	// Timestamp, if set, is used instead of the time of the commit being versioned, e.g. to honour
+	// SOURCE_DATE_EPOCH.
+	Timestamp       time.Time
+	TimestampSource TimestampSource
+	OnShallow       ShallowPolicy
+	// ShallowFallback lists tags to base the version on when the repository is a shallow clone with no
+	// tags in its history, and OnShallow is ShallowPolicyFallback.
+	ShallowFallback func() ([]string, error)
+	// Paths restricts the version to the files under these paths, relative to the root of the
+	// repository. Only commits and work tree changes touching them move the version past the base
+	// tag or mark it dirty.
+	Paths []string
+	// DirtyIgnore holds doublestar glob patterns, e.g. "bin/**", for files whose changes do not make
+	// the work tree dirty, such as generated files touched by the build.
+	DirtyIgnore []string
+	// ReleaseBranchPatterns holds doublestar glob patterns, e.g. DefaultReleaseBranchPatterns, for
+	// maintenance branches. Versions past the base tag on a matching branch bump the patch version
+	// rather than following BumpStrategy.
+	ReleaseBranchPatterns []string
+	// Branch is the name of the branch being versioned, for when HEAD is detached. It defaults to the
+	// branch checked out in Repo.
+	Branch string
+	// Satisfies is a semver range, such as ">=3.0.0 <4.0.0", which the version must be in. It
+	// guards against release branches calculating versions for another major or minor version.
+	Satisfies string
+	// GoModuleDir is the directory containing the go.mod file, relative to the root of the
+	// repository, for GetGoVersionWithOptions. It defaults to the root.
+	GoModuleDir string
+	// Explain, if set, receives a line for each decision made while calculating the version, such as
+	// which tags were skipped and how the base version was bumped.
+	Explain io.Writer
+}
+
+// explainf describes a step of the version calculation to `opts.Explain`, if set.
+func (opts LanguageVersionsOptions) explainf(format string, args ...interface{}) {
+	if opts.Explain != nil {
+		fmt.Fprintf(opts.Explain, format+"\n", args...)
+	}
+}
+
+// PreReleaseNumber controls the number in the prerelease component of versions for commits past
+// the base tag.
+type PreReleaseNumber string
+
+const (
+	// PreReleaseNumberTimestamp uses the commit timestamp, e.g. `1.3.0-alpha.1697040000`.
+	PreReleaseNumberTimestamp PreReleaseNumber = ""
+	// PreReleaseNumberDistance uses the number of commits since the base tag, e.g. `1.3.0-alpha.5`.
+	PreReleaseNumberDistance PreReleaseNumber = "distance"
+	// PreReleaseNumberDistanceTimestamp uses both, e.g. `1.3.0-alpha.5.1697040000`. As PEP440 only
+	// allows one pre-release number, the timestamp becomes a post-release for Python:
+	// `1.3.0a5.post1697040000`.
+	PreReleaseNumberDistanceTimestamp PreReleaseNumber = "distance-timestamp"
+)
+
+// ParsePreReleaseNumber converts a user supplied name into a PreReleaseNumber.
+func ParsePreReleaseNumber(name string) (PreReleaseNumber, error) {
+	switch strings.ToLower(name) {
+	case "", "timestamp":
+		return PreReleaseNumberTimestamp, nil
+	case "distance":
+		return PreReleaseNumberDistance, nil
+	case "distance-timestamp":
+		return PreReleaseNumberDistanceTimestamp, nil
+	default:
+		return "", fmt.Errorf("invalid prerelease number %q", name)
+	}
 }
 
 // GetLanguageVersionsWithOptions calculates the generic and Python-specific version numbers for the
 // given `commitish` based on the most recent tag, the status of the work tree with respect
 // to dirty files, and a timestamp.
 func GetLanguageVersionsWithOptions(opts LanguageVersionsOptions) (*LanguageVersions, error) {
-	repo := opts.Repo
-	commitish := opts.Commitish
-	omitCommitHash := opts.OmitCommitHash
-	releasePrefix := opts.ReleasePrefix
-	isPrerelease := opts.IsPreRelease
-	tagFilter := opts.TagFilter
+	details, err := GetVersionDetailsWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &details.LanguageVersions, nil
+}
 
-	versionComponents, err := versionAtCommitForRepo(repo, commitish, releasePrefix, isPrerelease, tagFilter)
+// GetVersionDetailsWithOptions calculates the same versions as GetLanguageVersionsWithOptions, and
+// additionally reports the base tag, commit and work tree state used to derive them.
+func GetVersionDetailsWithOptions(opts LanguageVersionsOptions) (*VersionDetails, error) {
+	versionComponents, err := versionAtCommitForRepo(opts)
 	if err != nil {
 		return nil, fmt.Errorf("getting language versions: %w", err)
 	}
 
+	return detailsFromComponents(opts, versionComponents)
+}
+
+// detailsFromComponents renders the language-specific versions for the given components.
+func detailsFromComponents(opts LanguageVersionsOptions,
+	versionComponents *versionComponents) (*VersionDetails, error) {
+	if err := checkConstraint(opts, versionComponents); err != nil {
+		return nil, err
+	}
+
+	omitCommitHash := opts.OmitCommitHash
+	isPrerelease := opts.IsPreRelease
+
 	// For most platforms we use major.minor.patch-prerelease_tag.timestamp
 	genericVersion := semver.Version{}
 	genericVersion.Major = versionComponents.Semver.Major
@@ -79,42 +184,33 @@ func GetLanguageVersionsWithOptions(opts LanguageVersionsOptions) (*LanguageVers
 
 	// a standard semantic version
 	preVersion := ""
-	// Python uses PEP440, but Pypi has some curiosities.
-	pythonPreVersion := ""
 	if len(genericVersion.Pre) != 0 {
 		var preSuffix string
 
 		if !versionComponents.IsExact {
-			preSuffix = fmt.Sprintf(".%d", versionComponents.Timestamp.UTC().Unix())
+			timestamp := versionComponents.Timestamp.UTC().Unix()
+			switch opts.PreReleaseNumber {
+			case PreReleaseNumberDistance:
+				preSuffix = fmt.Sprintf(".%d", versionComponents.Distance)
+			case PreReleaseNumberDistanceTimestamp:
+				preSuffix = fmt.Sprintf(".%d.%d", versionComponents.Distance, timestamp)
+			default:
+				preSuffix = fmt.Sprintf(".%d", timestamp)
+			}
 		} else {
 			if len(versionComponents.Semver.Pre) > 1 {
 				preSuffix = fmt.Sprintf(".%d", versionComponents.Semver.Pre[1].VersionNum)
 			}
 		}
 
-		// PEP440 (https://peps.python.org/pep-0440/) says pre-release parts MUST have a number in them,
-		// but we want to support tags like `v1.0.0-alpha`. If no number is present add `0` to keep PEP440
-		// happy.
-		var pythonPreSuffix string
-		if preSuffix == "" {
-			pythonPreSuffix = "0"
-		} else {
-			// Trim the initial "."
-			pythonPreSuffix = preSuffix[1:]
-		}
-
 		switch genericVersion.Pre[0].VersionStr {
 		case "dev":
-			pythonPreVersion = fmt.Sprintf("dev%s", pythonPreSuffix)
 			preVersion = fmt.Sprintf("-dev%s%s", preSuffix, shortHash)
 		case "alpha":
-			pythonPreVersion = fmt.Sprintf("a%s", pythonPreSuffix)
 			preVersion = fmt.Sprintf("-alpha%s%s", preSuffix, shortHash)
 		case "beta":
-			pythonPreVersion = fmt.Sprintf("b%s", pythonPreSuffix)
 			preVersion = fmt.Sprintf("-beta%s%s", preSuffix, shortHash)
 		case "rc":
-			pythonPreVersion = fmt.Sprintf("rc%s", pythonPreSuffix)
 			preVersion = fmt.Sprintf("-rc%s%s", preSuffix, shortHash)
 		default:
 			return nil, fmt.Errorf("prerelease string %q not valid semver string", genericVersion.Pre[0].VersionStr)
@@ -128,7 +224,6 @@ func GetLanguageVersionsWithOptions(opts LanguageVersionsOptions) (*LanguageVers
 			// If we didn't add a short hash or a preversion then we need to seperate with + not .
 			separator = "+"
 		}
-		pythonPreVersion = fmt.Sprintf("%s+dirty", pythonPreVersion)
 		preVersion = fmt.Sprintf("%s%sdirty", preVersion, separator)
 	}
 
@@ -148,15 +243,35 @@ func GetLanguageVersionsWithOptions(opts LanguageVersionsOptions) (*LanguageVers
 
 	// calculate versions for all languages
 	version := fmt.Sprintf("%s%s%s", baseVersion, preVersion, buildVersion)
-	pythonVersion := fmt.Sprintf("%s%s%s", baseVersion, pythonPreVersion, buildVersion)
 	jsVersion := fmt.Sprintf("v%s", version)
 	dotnetVersion := version
 
-	return &LanguageVersions{
-		SemVer:     version,
-		Python:     pythonVersion,
-		JavaScript: jsVersion,
-		DotNet:     dotnetVersion,
+	// Python uses PEP440, converted the same way as by ConvertVersion so that both agree. The build
+	// metadata of the tag follows any added to the prerelease, which `version` doesn't join validly.
+	parsed, err := semver.Parse(baseVersion + preVersion)
+	if err != nil {
+		return nil, fmt.Errorf("parsing calculated version %q: %w", version, err)
+	}
+	parsed.Build = append(parsed.Build, genericVersion.Build...)
+	pythonVersion, err := pythonVersion(parsed, false)
+	if err != nil {
+		return nil, fmt.Errorf("converting %q to a Python version: %w", version, err)
+	}
+
+	return &VersionDetails{
+		LanguageVersions: LanguageVersions{
+			SemVer:     version,
+			Python:     pythonVersion,
+			JavaScript: jsVersion,
+			DotNet:     dotnetVersion,
+			Java:       javaVersion(version),
+		},
+		BaseTag:    versionComponents.BaseTag,
+		IsExact:    versionComponents.IsExact,
+		Dirty:      versionComponents.Dirty,
+		ShortHash:  versionComponents.ShortHash,
+		Timestamp:  versionComponents.Timestamp,
+		DirtyFiles: versionComponents.DirtyFiles,
 	}, nil
 }
 
@@ -175,70 +290,169 @@ func GetLanguageVersions(repo *git.Repository, commitish plumbing.Revision, omit
 // versionComponents groups the various parameters which impact version calculation
 type versionComponents struct {
 	Semver    semver.Version
+	BaseTag   string
 	Dirty     bool
 	ShortHash string
 	Timestamp time.Time
-	IsExact   bool
+	// DirtyFiles lists the modified files which made the work tree dirty.
+	DirtyFiles []string
+	// Distance is the number of commits since the base tag. It is only calculated when needed.
+	Distance int
+	IsExact  bool
 }
 
 // versionAtCommitForRepo determines the version components on which the language-specific variants
 // are calculated from.
-func versionAtCommitForRepo(repo *git.Repository, commitish plumbing.Revision, releasePrefix string,
-	isPrerelease bool, tagFilter func(string) bool) (*versionComponents, error) {
+func versionAtCommitForRepo(opts LanguageVersionsOptions) (*versionComponents, error) {
+	opts, commit, err := resolveCommit(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	revision, err := repo.ResolveRevision(commitish)
+	baseVersion, baseTag, isExact, err := determineBaseVersion(opts, &commit.Hash)
 	if err != nil {
-		return nil, fmt.Errorf("error resolving commitish to reference: %w", err)
+		return nil, fmt.Errorf("error determining base versionComponents: %w", err)
 	}
 
-	commit, err := repo.CommitObject(*revision)
+	dirtyFiles, err := dirtyFilesForOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	explainDirtyFiles(opts, dirtyFiles)
+
+	return componentsFromBase(opts, commit, baseVersion, baseTag, isExact, dirtyFiles)
+}
+
+// resolveCommit returns the commit for `opts.Commitish`. If `opts.OnShallow` asks for the history
+// of a shallow clone to be fetched, this is done first and the returned options refer to the
+// reopened repository.
+func resolveCommit(opts LanguageVersionsOptions) (LanguageVersionsOptions, *object.Commit, error) {
+	if opts.OnShallow == ShallowPolicyFetch {
+		boundary, err := shallowBoundary(opts.Repo)
+		if err != nil {
+			return opts, nil, err
+		}
+		if len(boundary) > 0 {
+			if opts.Repo, err = unshallow(opts.Repo); err != nil {
+				return opts, nil, err
+			}
+			opts.explainf("fetched the full history of the shallow clone")
+		}
+	}
+
+	revision, err := opts.Repo.ResolveRevision(opts.Commitish)
 	if err != nil {
-		return nil, fmt.Errorf("error getting commit for revision: %w", err)
+		return opts, nil, fmt.Errorf("error resolving commitish to reference: %w", err)
 	}
 
-	baseVersion, isExact, err := determineBaseVersion(repo, revision, isPrerelease, tagFilter)
+	commit, err := opts.Repo.CommitObject(*revision)
 	if err != nil {
-		return nil, fmt.Errorf("error determining base versionComponents: %w", err)
+		return opts, nil, fmt.Errorf("error getting commit for revision: %w", err)
 	}
+	opts.explainf("commit: %s (resolved from %q)", commit.Hash, opts.Commitish)
+
+	return opts, commit, nil
+}
+
+// componentsFromBase calculates the version components of `commit` from its base version and tag.
+func componentsFromBase(opts LanguageVersionsOptions, commit *object.Commit, baseVersion string,
+	baseTag *plumbing.Reference, isExact bool, dirtyFiles []string) (*versionComponents, error) {
+	repo := opts.Repo
 
 	version, err := semver.Parse(baseVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing base versionComponents %q: %w", baseVersion, err)
 	}
+	paths := newPathFilter(opts.Paths)
+
+	var distance int
+	var since []*object.Commit
+	if !isExact && (paths != nil || opts.BumpStrategy == BumpStrategyConventional ||
+		opts.PreReleaseNumber != PreReleaseNumberTimestamp) {
+		// Only walk the commits since the base tag if something needs them, as it can be slow on
+		// large repositories.
+		since, err = commitsSinceTag(repo, commit, baseTag, opts.FirstParent)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits since base tag: %w", err)
+		}
+		if since, err = commitsTouching(repo, since, paths); err != nil {
+			return nil, fmt.Errorf("error filtering commits by path: %w", err)
+		}
+		distance = len(since)
+		if paths != nil {
+			opts.explainf("%d commits since the base tag touch %s", distance, strings.Join(paths, ", "))
+		} else {
+			opts.explainf("%d commits since the base tag", distance)
+		}
+
+		// If nothing under the paths changed since a tag in the repository, this is the tagged version.
+		if paths != nil && len(since) == 0 && baseTag != nil && !baseTag.Hash().IsZero() {
+			opts.explainf("nothing under the paths changed since %s, so using its version", baseTag.Name().Short())
+			isExact = true
+		}
+	}
+
 	if !isExact {
-		if version.Major == 0 {
-			version.Patch += 1
+		branch, isReleaseBranch, err := releaseBranch(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		level := bumpMinor
+		strategy := "default"
+		switch {
+		case isReleaseBranch:
+			level = bumpPatch
+			strategy = fmt.Sprintf("release branch %q", branch)
+		case opts.BumpStrategy == BumpStrategyConventional:
+			level = conventionalBumpLevel(since)
+			strategy = string(opts.BumpStrategy)
+		}
+		base := version.String()
+		applyBump(&version, level)
+		if version.Major == 0 && level > bumpPatch {
+			opts.explainf("bump: %s to %s (%s bump from the %s strategy, lowered a level as the major version is 0)",
+				base, version, level, strategy)
 		} else {
-			version.Minor += 1
-			version.Patch = 0
+			opts.explainf("bump: %s to %s (%s bump from the %s strategy)", base, version, level, strategy)
 		}
 		version.Pre = []semver.PRVersion{
 			{VersionStr: "alpha"},
 		}
 	}
 
-	if releasePrefix != "" {
-		newVersion, err := semver.Parse(releasePrefix)
+	if opts.ReleasePrefix != "" {
+		newVersion, err := semver.Parse(opts.ReleasePrefix)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing releasePrefix override %q: %w", releasePrefix, err)
+			return nil, fmt.Errorf("error parsing releasePrefix override %q: %w", opts.ReleasePrefix, err)
 		}
 
+		opts.explainf("version prefix: %d.%d.%d replaced by %d.%d.%d", version.Major, version.Minor, version.Patch,
+			newVersion.Major, newVersion.Minor, newVersion.Patch)
 		version.Major = newVersion.Major
 		version.Minor = newVersion.Minor
 		version.Patch = newVersion.Patch
 	}
 
-	isDirty, err := workTreeIsDirty(repo)
-	if err != nil {
-		return nil, err
+	timestamp, source := commitTimestamp(opts, commit)
+	if !isExact {
+		opts.explainf("timestamp: %d (%s)", timestamp.Unix(), source)
+	}
+
+	var baseTagName string
+	if baseTag != nil {
+		baseTagName = baseTag.Name().Short()
 	}
 
 	return &versionComponents{
-		Semver:    version,
-		Dirty:     isDirty,
-		ShortHash: revision.String()[:8],
-		Timestamp: commit.Committer.When,
-		IsExact:   isExact,
+		Semver:     version,
+		BaseTag:    baseTagName,
+		Dirty:      len(dirtyFiles) > 0,
+		DirtyFiles: dirtyFiles,
+		ShortHash:  commit.Hash.String()[:8],
+		Timestamp:  timestamp,
+		Distance:   distance,
+		IsExact:    isExact,
 	}, nil
 }
 
@@ -248,197 +462,133 @@ func versionAtCommitForRepo(repo *git.Repository, commitish plumbing.Revision, r
 //     is returned.
 //   - If `commitish` does not have an exact tag associated, the versionComponents component of the most
 //     recent exact tag is returned.
+//   - If the repository is a shallow clone, `opts.OnShallow` decides what happens.
 //   - Otherwise, "v0.0.0" is returned
 //
-// The second return value is true if an exact tag match was made.
+// The second return value is the matched tag, or nil if no tag was found. The third return value
+// is true if an exact tag match was made.
 //
-// If non-empty, `tagPrefix` works by filtering tags as if the repo
-// only had tags that start with this prefix.
-func determineBaseVersion(repo *git.Repository, revision *plumbing.Hash,
-	isPrerelease bool, tagFilter func(string) bool) (string, bool, error) {
+// If non-nil, `opts.TagFilter` works by filtering tags as if the repo
+// only had tags that match it. `opts.BaseStrategy` and `opts.FirstParent` control which
+// tag is picked when `commitish` is not tagged.
+func determineBaseVersion(opts LanguageVersionsOptions,
+	revision *plumbing.Hash) (string, *plumbing.Reference, bool, error) {
+	repo := opts.Repo
+
 	// Resolve the `commitish` we were given into a reference
 	commit, err := repo.CommitObject(*revision)
 	if err != nil {
-		return "", false, fmt.Errorf("error resolving reference: %w", err)
+		return "", nil, false, fmt.Errorf("error resolving reference: %w", err)
 	}
 
-	// First check whether we had a commit with an exact tag to start with
-	isExact, exactMatch, err := isExactTag(repo, commit.Hash, isPrerelease, tagFilter)
+	// Index the tags once up front rather than rescanning them for every commit we walk
+	tags, err := newTagIndex(repo, opts.tagSelector())
 	if err != nil {
-		return "", false, fmt.Errorf("isExactTag: %w", err)
+		return "", nil, false, err
 	}
-	if isExact {
-		return StripModuleTagPrefixes(exactMatch.Name().Short()), true, nil
+
+	explainTags(opts, tags)
+
+	// First check whether we had a commit with an exact tag to start with
+	if exactMatch := tags.exactTag(commit.Hash); exactMatch != nil {
+		opts.explainf("base tag: %s (exact match)", exactMatch.Name().Short())
+		return StripModuleTagPrefixes(exactMatch.Name().Short()), exactMatch, true, nil
 	}
 
 	// If not, find the most recent tag
-	hasRecent, recentMatch, err := mostRecentTag(repo, commit.Hash, isPrerelease, tagFilter)
+	recentMatch, err := tags.selectTag(repo, commit.Hash, opts.BaseStrategy, opts.FirstParent)
 	if err != nil {
-		return "", false, fmt.Errorf("mostRecentTag: %w", err)
+		return "", nil, false, fmt.Errorf("selecting base tag: %w", err)
 	}
-	if hasRecent {
-		return StripModuleTagPrefixes(recentMatch.Name().Short()), false, nil
+	if recentMatch != nil {
+		opts.explainf("base tag: %s (not exact, %s)", recentMatch.Name().Short(), describeBaseStrategy(opts))
+		return StripModuleTagPrefixes(recentMatch.Name().Short()), recentMatch, false, nil
 	}
 
-	// Fallback if we don't have anything
-	return "0.0.0", false, nil
-}
-
-// stripModuleTagPrefixes returns the last component of a path. This is used to
-// resolve the tag format used in pulumi repos of "module/versionComponents" to a simple
-// versionComponents.
-func StripModuleTagPrefixes(tag string) string {
-	_, versionComponent := path.Split(tag)
-	return strings.TrimPrefix(versionComponent, "v")
-}
-
-// isExactTag returns true if the given hash has a tag associated with it. If
-// true is returned, the second return value is a reference representing the tag.
-func isExactTag(repo *git.Repository, hash plumbing.Hash,
-	isPrerease bool, tagFilter func(string) bool) (bool, *plumbing.Reference, error) {
-	tags, err := repo.Tags()
-	if err != nil {
-		return false, nil, fmt.Errorf("error listing tags: %w", err)
-	}
-
-	var exactTag *plumbing.Reference = nil
-	if err := tags.ForEach(func(ref *plumbing.Reference) error {
-		if ref.Type() != plumbing.HashReference {
-			// Skip symbolic refs, for simplicity. We're not going to try and recursively resolve these.
-			return nil
-		}
-
-		refName := ref.Name().String()
-
-		// if we are marking the release as a pre-release, then we want to take into account
-		// the beta and rc versions
-		// if we are in a normal release cycle then we want to skip these
-		// we want to ignore the beta and rc tags - they are the next major version so we
-		// don't want to use these in our calculations of the current release variant
-		if !isPrerease && strings.Contains(refName, "beta") ||
-			!isPrerease && strings.Contains(refName, "rc") {
-			return nil
-		}
-
-		// if tagFilter such as "sdk/" prefix is specified, we
-		// only consider refs that match.
-		if tagFilter != nil && !tagFilter(strings.TrimPrefix(refName, "refs/tags/")) {
-			return nil
-		}
-
-		obj, err := repo.TagObject(ref.Hash())
-		switch err {
-		case nil:
-			// This is an annotated tag, check the hash of the target of the tag object
-			if obj.Target == hash {
-				exactTag = ref
-				return storer.ErrStop
-			}
-
-		case plumbing.ErrObjectNotFound:
-			// Not a tag object, pointing directly to the commit
-			if ref.Hash() == hash {
-				exactTag = ref
-				return storer.ErrStop
+	// A shallow clone may simply not have fetched the history containing the tag
+	if tags.isShallow() {
+		switch opts.OnShallow {
+		case ShallowPolicyIgnore:
+			opts.explainf("no tags found in the history of the shallow clone, ignoring")
+		case ShallowPolicyFallback:
+			baseVersion, fallbackTag, err := fallbackBaseVersion(opts)
+			if err != nil {
+				return "", nil, false, err
 			}
-
+			opts.explainf("base tag: %s (not exact, fallback for a shallow clone)", fallbackTag.Name().Short())
+			return baseVersion, fallbackTag, false, nil
 		default:
-			return err
+			return "", nil, false, fmt.Errorf("no tags found in the history of a shallow clone; " +
+				"fetch the full history (e.g. fetch-depth: 0) or provide a fallback")
 		}
-
-		return nil
-	}); err != nil {
-		return false, nil, fmt.Errorf("error iterating on tags: %w", err)
 	}
 
-	return exactTag != nil, exactTag, nil
+	// Fallback if we don't have anything
+	opts.explainf("base tag: none found, starting from 0.0.0")
+	return "0.0.0", nil, false, nil
 }
 
-// mostRecentTag returns a reference to the most recent tag in which the given commit reference is included.
-// The first return value is true if there is a tag matching these criteria, and false if not. If the
-// first return is true, the second value contains a reference to the appropriate tag.
-func mostRecentTag(repo *git.Repository, ref plumbing.Hash, isPrerelease bool,
-	tagFilter func(string) bool) (bool, *plumbing.Reference, error) {
-	commit, err := repo.CommitObject(ref)
-	if err != nil {
-		return false, nil, fmt.Errorf("no commit for ref %q: %w", ref, err)
+// explainTags describes which tags were considered for version calculation, and why any were
+// skipped.
+func explainTags(opts LanguageVersionsOptions, tags *tagIndex) {
+	if opts.Explain == nil {
+		return
 	}
 
-	var mostRecentTag *plumbing.Reference
-	walker := object.NewCommitPreorderIter(commit, nil, nil)
-	err = walker.ForEach(func(commit *object.Commit) error {
-		isExact, exact, err := isExactTag(repo, commit.Hash, isPrerelease, tagFilter)
-		if err != nil {
-			return err
-		}
-
-		if !isExact {
-			return nil
-		}
-
-		mostRecentTag = exact
-		return storer.ErrStop
-	})
-
-	return mostRecentTag != nil, mostRecentTag, err
-}
-
-// workTreeIsDirty returns whether the worktree associated with the given repository
-// has local modifications.
-func workTreeIsDirty(repo *git.Repository) (bool, error) {
-	// Using global viper state as "debug" is defined on the global Viper in main.go.
-	debug := viperlib.GetBool("debug")
-	workTree, err := repo.Worktree()
-	if err != nil {
-		return false, fmt.Errorf("looking up worktree: %w", err)
+	// Some storages list tags in a random order, so sort them to keep explanations reproducible
+	considered := make([]string, 0, len(tags.considered))
+	for _, ref := range tags.considered {
+		considered = append(considered, ref.Name().Short())
 	}
-
-	if _, ok := repo.Storer.(*filesystem.Storage); !ok {
-		status, err := workTree.Status()
-		if err != nil {
-			return false, fmt.Errorf("error getting git worktree status: %w", err)
-		}
-		if debug {
-			fmt.Println(status)
-		}
-		return !status.IsClean(), err
+	sort.Strings(considered)
+	for _, name := range considered {
+		opts.explainf("tag considered: %s", name)
 	}
 
-	// we need to refresh the index before we try and check diff-files
-	// diff-files doesn't check the contents changing, it only checks
-	// the stat changes so if the file was "touched" in anyway, then diff-files
-	// *could* show it has changed but a git status then git diff-files wouldn't
-	// because git status causes a reindex
-	c := exec.Command("git", "update-index", "-q", "--refresh")
-	c.Dir = workTree.Filesystem.Root()
-	output, err := c.Output()
-	if err != nil {
-		if debug {
-			fmt.Println(err)
-			fmt.Println("Error updating git index - forcing isDirty")
-		}
-		return true, nil
+	skipped := append([]skippedTag(nil), tags.skipped...)
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].name < skipped[j].name })
+	for _, tag := range skipped {
+		opts.explainf("tag skipped: %s (%s)", tag.name, tag.reason)
 	}
-	if debug {
-		fmt.Println(string(output))
+}
+
+// describeBaseStrategy describes how the base tag is picked for commits which are not tagged.
+func describeBaseStrategy(opts LanguageVersionsOptions) string {
+	switch {
+	case opts.BaseStrategy == BaseStrategyHighestReachable && opts.FirstParent:
+		return "highest version following first parents"
+	case opts.BaseStrategy == BaseStrategyHighestReachable:
+		return "highest version reachable"
+	case opts.FirstParent:
+		return "first tag following first parents"
+	case opts.BaseStrategy == BaseStrategyNearest:
+		return "nearest tag by commit count"
+	default:
+		return "first tag found walking history in pre-order"
 	}
+}
 
-	// Fast-path if the underlying filesystem is on disk since Status is really slow
-	// on larger repositories.
-	c = exec.Command("git", "diff-files", "--name-status", "--ignore-space-at-eol")
-	c.Dir = workTree.Filesystem.Root()
-	output, err = c.Output()
-	if err != nil {
-		if ee, ok := err.(*exec.ExitError); ok {
-			if debug {
-				fmt.Println(string(ee.Stderr))
-			}
-			return true, nil
-		}
-		return false, err
+// explainDirtyFiles describes the files which made the work tree dirty.
+func explainDirtyFiles(opts LanguageVersionsOptions, dirtyFiles []string) {
+	if len(dirtyFiles) == 0 {
+		opts.explainf("work tree: clean")
 	}
-	if debug {
-		fmt.Println(string(output))
+	for _, file := range dirtyFiles {
+		opts.explainf("work tree: dirty from %s", file)
 	}
-	return len(output) > 0, nil
+}
+
+// ModuleTagPrefix returns the module path of a tag in the "module/version" format used in pulumi
+// repos, e.g. "sdk/nodejs" for "sdk/nodejs/v2.1.0". Tags without a module path return "".
+func ModuleTagPrefix(tag string) string {
+	modulePath, _ := path.Split(tag)
+	return strings.TrimSuffix(modulePath, "/")
+}
+
+// stripModuleTagPrefixes returns the last component of a path. This is used to
+// resolve the tag format used in pulumi repos of "module/versionComponents" to a simple
+// versionComponents.
+func StripModuleTagPrefixes(tag string) string {
+	_, versionComponent := path.Split(tag)
+	return strings.TrimPrefix(versionComponent, "v")
 }
@@ -1,6 +1,7 @@
 package gitversion
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -18,6 +19,12 @@ func TestStripModuleTagPrefixes(t *testing.T) {
 	require.Equal(t, "2.1.0", StripModuleTagPrefixes("sdk/nodejs/v2.1.0"))
 }
 
+func TestModuleTagPrefix(t *testing.T) {
+	require.Equal(t, "", ModuleTagPrefix("v0.0.0"))
+	require.Equal(t, "sdk", ModuleTagPrefix("sdk/v2.1.0"))
+	require.Equal(t, "sdk/nodejs", ModuleTagPrefix("sdk/nodejs/v2.1.0"))
+}
+
 func TestMostRecentTag(t *testing.T) {
 	t.Run("Repo with commit after tag", func(t *testing.T) {
 		repo, err := testRepoCreate()
@@ -30,9 +37,10 @@ func TestMostRecentTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, headRef)
 
-		hasMostRecent, mostRecent, err := mostRecentTag(repo, headRef.Hash(), false, nil)
+		tags, err := newTagIndex(repo, tagSelector{})
+		require.NoError(t, err)
+		mostRecent, err := tags.mostRecentTag(repo, headRef.Hash())
 		require.NoError(t, err)
-		require.True(t, hasMostRecent)
 		require.NotNil(t, mostRecent)
 		require.Equal(t, "refs/tags/v1.0.0", mostRecent.Name().String())
 	})
@@ -44,9 +52,10 @@ func TestMostRecentTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, head)
 
-		hasMostRecent, mostRecent, err := mostRecentTag(repo, head, false, nil)
+		tags, err := newTagIndex(repo, tagSelector{})
+		require.NoError(t, err)
+		mostRecent, err := tags.mostRecentTag(repo, head)
 		require.NoError(t, err)
-		require.False(t, hasMostRecent)
 		require.Nil(t, mostRecent)
 	})
 
@@ -79,10 +88,11 @@ func TestMostRecentTag(t *testing.T) {
 		}
 
 		isMostRecent := func(expected string, preRelease bool, tagFilter func(string) bool) {
-			hasMostRecent, mostRecent, err := mostRecentTag(repo,
-				headRef.Hash(), preRelease, tagFilter)
+			tags, err := newTagIndex(repo, tagSelector{isPrerelease: preRelease, filter: tagFilter})
+			require.NoError(t, err)
+			mostRecent, err := tags.mostRecentTag(repo, headRef.Hash())
 			require.NoError(t, err)
-			require.True(t, hasMostRecent)
+			require.NotNil(t, mostRecent)
 			require.Equal(t, expected, mostRecent.Name().String())
 		}
 
@@ -93,6 +103,93 @@ func TestMostRecentTag(t *testing.T) {
 	})
 }
 
+func TestSelectTag(t *testing.T) {
+	selectTag := func(t *testing.T, repo *git.Repository, strategy BaseStrategy, firstParent bool) string {
+		headRef, err := repo.Head()
+		require.NoError(t, err)
+
+		tags, err := newTagIndex(repo, tagSelector{})
+		require.NoError(t, err)
+
+		tag, err := tags.selectTag(repo, headRef.Hash(), strategy, firstParent)
+		require.NoError(t, err)
+		require.NotNil(t, tag)
+		return tag.Name().Short()
+	}
+
+	t.Run("Release branch merged as second parent", func(t *testing.T) {
+		repo := testRepoMergedReleaseBranch(t, false)
+
+		require.Equal(t, "v1.0.0", selectTag(t, repo, BaseStrategyPreorder, false))
+		require.Equal(t, "v1.0.1", selectTag(t, repo, BaseStrategyNearest, false))
+		require.Equal(t, "v1.0.0", selectTag(t, repo, BaseStrategyNearest, true))
+		require.Equal(t, "v1.0.1", selectTag(t, repo, BaseStrategyHighestReachable, false))
+		require.Equal(t, "v1.0.0", selectTag(t, repo, BaseStrategyHighestReachable, true))
+	})
+
+	t.Run("Release branch merged as first parent", func(t *testing.T) {
+		repo := testRepoMergedReleaseBranch(t, true)
+
+		require.Equal(t, "v1.0.1", selectTag(t, repo, BaseStrategyPreorder, false))
+		require.Equal(t, "v1.0.1", selectTag(t, repo, BaseStrategyNearest, false))
+		require.Equal(t, "v1.0.1", selectTag(t, repo, BaseStrategyNearest, true))
+	})
+
+	t.Run("Nearest tag of a linear history", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		repo, err = testRepoSingleCommitPastRelease(repo)
+		require.NoError(t, err)
+
+		require.Equal(t, "v1.0.0", selectTag(t, repo, BaseStrategyNearest, false))
+		require.Equal(t, "v1.0.0", selectTag(t, repo, BaseStrategyPreorder, true))
+	})
+
+	t.Run("Highest tag is older than the nearest", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		repo, err = testRepoWithTags(repo, []string{"v1.0.0", "v1.2.0", "v1.1.3", "v1.2.0-alpha.1"})
+		require.NoError(t, err)
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+		addFile(t, workTree, "after.txt", "after")
+		_, err = workTree.Commit("After the tags", &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+
+		require.Equal(t, "v1.2.0-alpha.1", selectTag(t, repo, BaseStrategyNearest, false))
+		require.Equal(t, "v1.2.0", selectTag(t, repo, BaseStrategyHighestReachable, false))
+		require.Equal(t, "v1.2.0", selectTag(t, repo, BaseStrategyHighestReachable, true))
+	})
+
+	t.Run("Nearest tag with no tags", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		head, err := testRepoSingleCommit(repo)
+		require.NoError(t, err)
+
+		tags, err := newTagIndex(repo, tagSelector{})
+		require.NoError(t, err)
+
+		tag, err := tags.selectTag(repo, head, BaseStrategyNearest, false)
+		require.NoError(t, err)
+		require.Nil(t, tag)
+	})
+
+	t.Run("Version from nearest tag", func(t *testing.T) {
+		repo := testRepoMergedReleaseBranch(t, false)
+
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:           repo,
+			Commitish:      plumbing.Revision("HEAD"),
+			OmitCommitHash: true,
+			BaseStrategy:   BaseStrategyNearest,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "v1.0.1", details.BaseTag)
+		require.Equal(t, "1.1.0-alpha.0", details.SemVer)
+	})
+}
+
 func TestIsExactTag(t *testing.T) {
 	repo, err := testRepoCreate()
 	require.NoError(t, err)
@@ -104,11 +201,14 @@ func TestIsExactTag(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, headRef)
 
-	t.Run("Not an exact tag", func(t *testing.T) {
-		isExact, exact, err := isExactTag(repo, headRef.Hash(), false, nil)
+	exactTag := func(t *testing.T, hash plumbing.Hash, isPrerelease bool) *plumbing.Reference {
+		tags, err := newTagIndex(repo, tagSelector{isPrerelease: isPrerelease})
 		require.NoError(t, err)
-		require.Nil(t, exact)
-		require.False(t, isExact)
+		return tags.exactTag(hash)
+	}
+
+	t.Run("Not an exact tag", func(t *testing.T) {
+		require.Nil(t, exactTag(t, headRef.Hash(), false))
 	})
 
 	t.Run("With exact tag - prerelease", func(t *testing.T) {
@@ -116,10 +216,7 @@ func TestIsExactTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, exactRef)
 
-		isExact, exact, err := isExactTag(repo, exactRef.Hash(), false, nil)
-		require.NoError(t, err)
-		require.NotNil(t, exact)
-		require.True(t, isExact)
+		require.NotNil(t, exactTag(t, exactRef.Hash(), false))
 	})
 
 	t.Run("With exact tag", func(t *testing.T) {
@@ -127,10 +224,7 @@ func TestIsExactTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, exactRef)
 
-		isExact, exact, err := isExactTag(repo, exactRef.Hash(), false, nil)
-		require.NoError(t, err)
-		require.NotNil(t, exact)
-		require.True(t, isExact)
+		require.NotNil(t, exactTag(t, exactRef.Hash(), false))
 	})
 
 	t.Run("Don't skip the beta tag as it's a pre-release", func(t *testing.T) {
@@ -138,10 +232,7 @@ func TestIsExactTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, exactRef)
 
-		isExact, exact, err := isExactTag(repo, exactRef.Hash(), true, nil)
-		require.NoError(t, err)
-		require.NotNil(t, exact)
-		require.True(t, isExact)
+		require.NotNil(t, exactTag(t, exactRef.Hash(), true))
 	})
 
 	t.Run("Skip the beta as it's a normal release", func(t *testing.T) {
@@ -149,10 +240,7 @@ func TestIsExactTag(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, exactRef)
 
-		isExact, exact, err := isExactTag(repo, exactRef.Hash(), false, nil)
-		require.NoError(t, err)
-		require.Nil(t, exact)
-		require.False(t, isExact)
+		require.Nil(t, exactTag(t, exactRef.Hash(), false))
 	})
 }
 
@@ -169,9 +257,9 @@ func TestIsWorktreeDirty(t *testing.T) {
 	require.NotEmpty(t, head)
 
 	t.Run("Working tree is clean", func(t *testing.T) {
-		clean, err := workTreeIsDirty(repo)
+		dirty, err := workTreeDirtyFiles(repo, dirtyFilter{})
 		require.NoError(t, err)
-		require.False(t, clean)
+		require.Empty(t, dirty)
 	})
 
 	// Add a file but don't commit it
@@ -188,9 +276,9 @@ func TestIsWorktreeDirty(t *testing.T) {
 	}
 
 	t.Run("Working tree is dirty", func(t *testing.T) {
-		dirty, err := workTreeIsDirty(repo)
+		dirty, err := workTreeDirtyFiles(repo, dirtyFilter{})
 		require.NoError(t, err)
-		require.True(t, dirty)
+		require.Equal(t, []string{"hello-world"}, dirty)
 	})
 }
 
@@ -211,6 +299,7 @@ func TestGetVersion(t *testing.T) {
 
 		require.Equal(t, "0.0.1-alpha.0+68804cfa", version.SemVer)
 		require.Equal(t, "0.0.1-alpha.0+68804cfa", version.DotNet)
+		require.Equal(t, "0.0.1-alpha.0", version.Java)
 		require.Equal(t, "v0.0.1-alpha.0+68804cfa", version.JavaScript)
 		require.Equal(t, "0.0.1a0", version.Python)
 	})
@@ -235,6 +324,7 @@ func TestGetVersion(t *testing.T) {
 
 		require.Equal(t, "1.0.0", version.SemVer)
 		require.Equal(t, "1.0.0", version.DotNet)
+		require.Equal(t, "1.0.0", version.Java)
 		require.Equal(t, "v1.0.0", version.JavaScript)
 		require.Equal(t, "1.0.0", version.Python)
 	})
@@ -266,6 +356,7 @@ func TestGetVersion(t *testing.T) {
 
 		require.Equal(t, "1.1.0-alpha.0+9fa804e8", version.SemVer)
 		require.Equal(t, "1.1.0-alpha.0+9fa804e8", version.DotNet)
+		require.Equal(t, "1.1.0-alpha.0", version.Java)
 		require.Equal(t, "v1.1.0-alpha.0+9fa804e8", version.JavaScript)
 		require.Equal(t, "1.1.0a0", version.Python)
 	})
@@ -302,6 +393,7 @@ func TestGetVersion(t *testing.T) {
 
 		require.Equal(t, "1.1.0-alpha.0+9fa804e8.dirty", version.SemVer)
 		require.Equal(t, "1.1.0-alpha.0+9fa804e8.dirty", version.DotNet)
+		require.Equal(t, "1.1.0-alpha.0-SNAPSHOT", version.Java)
 		require.Equal(t, "v1.1.0-alpha.0+9fa804e8.dirty", version.JavaScript)
 		require.Equal(t, "1.1.0a0+dirty", version.Python)
 	})
@@ -328,6 +420,7 @@ func TestGetVersion(t *testing.T) {
 
 		require.Equal(t, "0.0.1-alpha.0+68804cfa.dirty", version.SemVer)
 		require.Equal(t, "0.0.1-alpha.0+68804cfa.dirty", version.DotNet)
+		require.Equal(t, "0.0.1-alpha.0-SNAPSHOT", version.Java)
 		require.Equal(t, "v0.0.1-alpha.0+68804cfa.dirty", version.JavaScript)
 		require.Equal(t, "0.0.1a0+dirty", version.Python)
 	})
@@ -557,3 +650,151 @@ func TestGetVersion(t *testing.T) {
 		require.Equal(t, "1.0.0+1abc.345.whoop", version.Python)
 	})
 }
+
+func TestGetVersionDetails(t *testing.T) {
+	t.Run("Repo with commit after tag", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+
+		repo, err = testRepoWithTags(repo, []string{"sdk/v1.0.0"})
+		require.NoError(t, err)
+
+		addFile(t, workTree, "hello.txt", "Hello world")
+		_, err = workTree.Commit("Next commit", &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:      repo,
+			Commitish: plumbing.Revision("HEAD"),
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "sdk/v1.0.0", details.BaseTag)
+		require.False(t, details.IsExact)
+		require.False(t, details.Dirty)
+		require.Len(t, details.ShortHash, 8)
+		require.Equal(t, "v"+details.SemVer, details.JavaScript)
+	})
+
+	t.Run("Repo with exact tag and dirty", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+
+		repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+		require.NoError(t, err)
+
+		err = writeFile(workTree.Filesystem, "hello-world", "Hello World 2")
+		require.NoError(t, err)
+
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:      repo,
+			Commitish: plumbing.Revision("HEAD"),
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "v1.0.0", details.BaseTag)
+		require.True(t, details.IsExact)
+		require.True(t, details.Dirty)
+		require.Equal(t, "1.0.0+dirty", details.SemVer)
+	})
+
+	t.Run("Repo with no tags", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		_, err = testRepoSingleCommit(repo)
+		require.NoError(t, err)
+
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:      repo,
+			Commitish: plumbing.Revision("HEAD"),
+		})
+		require.NoError(t, err)
+
+		require.Empty(t, details.BaseTag)
+		require.False(t, details.IsExact)
+		require.Equal(t, "68804cfa", details.ShortHash)
+	})
+}
+
+func TestGetVersionCommitDistance(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		addFile(t, workTree, fmt.Sprintf("change-%d.txt", i), "change")
+		_, err = workTree.Commit("Change", &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+	}
+
+	getVersion := func(number PreReleaseNumber) *LanguageVersions {
+		version, err := GetLanguageVersionsWithOptions(LanguageVersionsOptions{
+			Repo:             repo,
+			Commitish:        plumbing.Revision("HEAD"),
+			OmitCommitHash:   true,
+			PreReleaseNumber: number,
+		})
+		require.NoError(t, err)
+		return version
+	}
+
+	version := getVersion(PreReleaseNumberDistance)
+	require.Equal(t, "1.1.0-alpha.3", version.SemVer)
+	require.Equal(t, "1.1.0-alpha.3", version.DotNet)
+	require.Equal(t, "v1.1.0-alpha.3", version.JavaScript)
+	require.Equal(t, "1.1.0a3", version.Python)
+
+	version = getVersion(PreReleaseNumberDistanceTimestamp)
+	require.Equal(t, "1.1.0-alpha.3.0", version.SemVer)
+	require.Equal(t, "1.1.0-alpha.3.0", version.DotNet)
+	require.Equal(t, "v1.1.0-alpha.3.0", version.JavaScript)
+	require.Equal(t, "1.1.0a3.post0", version.Python)
+
+	version = getVersion(PreReleaseNumberTimestamp)
+	require.Equal(t, "1.1.0-alpha.0", version.SemVer)
+	require.Equal(t, "1.1.0a0", version.Python)
+}
+
+func TestGetVersionExplain(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0", "v1.1.0-beta.1", "sdk/v2.0.0"})
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "after.txt", "after")
+	_, err = workTree.Commit("After the tags", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	var explanation strings.Builder
+	_, err = GetVersionDetailsWithOptions(LanguageVersionsOptions{
+		Repo:          repo,
+		Commitish:     plumbing.Revision("HEAD"),
+		ReleasePrefix: "3.0.0",
+		TagFilter:     func(tag string) bool { return strings.HasPrefix(tag, "v") },
+		Explain:       &explanation,
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(explanation.String()), "\n")
+	require.Contains(t, lines[0], "commit: ")
+	require.Equal(t, []string{
+		"tag considered: v1.0.0",
+		"tag skipped: sdk/v2.0.0 (does not match the tag filter)",
+		"tag skipped: v1.1.0-beta.1 (prerelease \"beta\" is only a candidate for prereleases)",
+		"base tag: v1.0.0 (not exact, first tag found walking history in pre-order)",
+		"work tree: clean",
+		"bump: 1.0.0 to 1.1.0 (minor bump from the default strategy)",
+		"version prefix: 1.1.0 replaced by 3.0.0",
+		"timestamp: 0 (committer time)",
+	}, lines[1:])
+}
@@ -0,0 +1,131 @@
+package gitversion
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GetGoVersionWithOptions returns the version the Go toolchain would resolve for the module in
+// `opts.GoModuleDir` at `opts.Commitish`: the tag itself when the commit is tagged, otherwise a
+// pseudo-version such as `v1.2.4-0.20231010120000-abcdef123456`.
+//
+// The base tag is picked the way `go` picks it, so most other options are ignored: only tags for
+// the module directory with the major version of its go.mod module path are considered, the highest
+// one reachable from the commit is used, and the timestamp is always the committer time. Work tree
+// changes are not reflected, as pseudo-versions only describe commits.
+func GetGoVersionWithOptions(opts LanguageVersionsOptions) (string, error) {
+	opts, commit, err := resolveCommit(opts)
+	if err != nil {
+		return "", err
+	}
+
+	modPath, err := goModulePath(commit, opts.GoModuleDir)
+	if err != nil {
+		return "", err
+	}
+	_, pathMajor, ok := module.SplitPathVersion(modPath)
+	if !ok {
+		return "", fmt.Errorf("invalid module path %q", modPath)
+	}
+	opts.explainf("go module: %s", modPath)
+
+	prefix := ""
+	if opts.GoModuleDir != "" && opts.GoModuleDir != "." {
+		prefix = path.Clean(opts.GoModuleDir) + "/"
+	}
+	tags, err := newTagIndex(opts.Repo, tagSelector{
+		filter:   func(name string) bool { return goTagVersion(name, prefix) != "" },
+		policies: map[string]TagPolicy{"*": TagPolicyRelease},
+	})
+	if err != nil {
+		return "", err
+	}
+	explainTags(opts, tags)
+
+	// Tags with another major version are ignored by `go`, which is expected while a new major
+	// version is in progress. A tag on the commit itself, or for a later major version than go.mod
+	// declares, is a mistake.
+	moduleMajor := module.PathMajorPrefix(pathMajor)
+	if moduleMajor == "" {
+		moduleMajor = "v1"
+	}
+	var base, exact string
+	err = tags.walk(commit, false, func(c *object.Commit) error {
+		for _, ref := range tags.byCommit[c.Hash] {
+			version := goTagVersion(ref.Name().Short(), prefix)
+			if err := module.CheckPathMajor(version, pathMajor); err != nil {
+				if c.Hash == commit.Hash || semver.Compare(semver.Major(version), moduleMajor) > 0 {
+					return fmt.Errorf("tag %s does not match go.mod module %s: %w", ref.Name().Short(), modPath, err)
+				}
+				continue
+			}
+			if c.Hash == commit.Hash && semver.Compare(version, exact) > 0 {
+				exact = version
+			}
+			if semver.Compare(version, base) > 0 {
+				base = version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if exact != "" {
+		opts.explainf("base tag: %s%s (exact match)", prefix, exact)
+		return exact, nil
+	}
+	if base == "" {
+		opts.explainf("base tag: none for major version %s", goMajor(pathMajor))
+	} else {
+		opts.explainf("base tag: %s%s (not exact, highest reachable tag)", prefix, base)
+	}
+	rev := commit.Hash.String()[:12]
+	return module.PseudoVersion(module.PathMajorPrefix(pathMajor), base, commit.Committer.When, rev), nil
+}
+
+// goModulePath returns the module path declared by the go.mod file in `dir` at `commit`.
+func goModulePath(commit *object.Commit, dir string) (string, error) {
+	name := path.Join(dir, "go.mod")
+	file, err := commit.File(name)
+	if err != nil {
+		return "", fmt.Errorf("reading %s at %s: %w", name, commit.Hash.String()[:8], err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return "", fmt.Errorf("reading %s at %s: %w", name, commit.Hash.String()[:8], err)
+	}
+	modPath := modfile.ModulePath([]byte(content))
+	if modPath == "" {
+		return "", fmt.Errorf("no module path in %s", name)
+	}
+	return modPath, nil
+}
+
+// goTagVersion returns the version of the tag `name` for the module whose tags start with
+// `prefix`, or "" if it is not a tag `go` would use for that module.
+func goTagVersion(name, prefix string) string {
+	if !strings.HasPrefix(name, prefix) {
+		return ""
+	}
+	version := strings.TrimPrefix(name, prefix)
+	if semver.Canonical(version) != version || semver.Build(version) != "" {
+		return ""
+	}
+	return version
+}
+
+// goMajor returns the major version implied by the go.mod `pathMajor`, such as "/v2".
+func goMajor(pathMajor string) string {
+	if major := module.PathMajorPrefix(pathMajor); major != "" {
+		return major
+	}
+	return "v0 or v1"
+}
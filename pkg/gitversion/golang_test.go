@@ -0,0 +1,120 @@
+package gitversion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetGoVersion(t *testing.T) {
+	when := time.Date(2023, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	// commit writes `files` and commits them, tagging the commit with `tags`.
+	commit := func(t *testing.T, repo *git.Repository, files map[string]string, tags ...string) string {
+		workTree, err := repo.Worktree()
+		require.NoError(t, err)
+		for name, content := range files {
+			addFile(t, workTree, name, content)
+		}
+		when = when.Add(time.Hour)
+		hash, err := workTree.Commit("Change", &git.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@localhost", When: when},
+		})
+		require.NoError(t, err)
+		for _, tag := range tags {
+			_, err := repo.CreateTag(tag, hash, nil)
+			require.NoError(t, err)
+		}
+		return hash.String()[:12]
+	}
+
+	getVersion := func(repo *git.Repository, dir string) (string, error) {
+		return GetGoVersionWithOptions(LanguageVersionsOptions{
+			Repo:        repo,
+			Commitish:   plumbing.Revision("HEAD"),
+			GoModuleDir: dir,
+		})
+	}
+
+	t.Run("Untagged", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		hash := commit(t, repo, map[string]string{"go.mod": "module example.com/m\n"})
+
+		version, err := getVersion(repo, "")
+		require.NoError(t, err)
+		require.Equal(t, "v0.0.0-20231010130000-"+hash, version)
+	})
+
+	t.Run("Release and prerelease tags", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		commit(t, repo, map[string]string{"go.mod": "module example.com/m\n"}, "v1.2.3")
+
+		version, err := getVersion(repo, "")
+		require.NoError(t, err)
+		require.Equal(t, "v1.2.3", version)
+
+		hash := commit(t, repo, map[string]string{"a.txt": "a"}, "not-a-version", "v1.2")
+		version, err = getVersion(repo, "")
+		require.NoError(t, err)
+		require.Equal(t, "v1.2.4-0.20231010150000-"+hash, version)
+
+		commit(t, repo, map[string]string{"b.txt": "b"}, "v1.3.0-rc.1")
+		hash = commit(t, repo, map[string]string{"c.txt": "c"})
+		version, err = getVersion(repo, "")
+		require.NoError(t, err)
+		require.Equal(t, "v1.3.0-rc.1.0.20231010170000-"+hash, version)
+	})
+
+	t.Run("Nested module", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		commit(t, repo, map[string]string{
+			"go.mod":     "module example.com/m\n",
+			"sdk/go.mod": "module example.com/m/sdk/v2\n",
+		}, "v5.0.0", "sdk/v1.9.0", "sdk/v2.1.0")
+		hash := commit(t, repo, map[string]string{"sdk/a.txt": "a"})
+
+		version, err := getVersion(repo, "sdk")
+		require.NoError(t, err)
+		require.Equal(t, "v2.1.1-0.20231010190000-"+hash, version)
+	})
+
+	t.Run("New major version in progress", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		commit(t, repo, map[string]string{"go.mod": "module example.com/m\n"}, "v1.5.0")
+		hash := commit(t, repo, map[string]string{"go.mod": "module example.com/m/v2\n"})
+
+		version, err := getVersion(repo, "")
+		require.NoError(t, err)
+		require.Equal(t, "v2.0.0-20231010210000-"+hash, version)
+	})
+
+	t.Run("Tag disagrees with go.mod", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		commit(t, repo, map[string]string{"go.mod": "module example.com/m\n"}, "v2.0.0")
+
+		_, err = getVersion(repo, "")
+		require.ErrorContains(t, err, "tag v2.0.0 does not match go.mod module example.com/m")
+
+		commit(t, repo, map[string]string{"a.txt": "a"})
+		_, err = getVersion(repo, "")
+		require.ErrorContains(t, err, "tag v2.0.0 does not match go.mod module example.com/m")
+	})
+
+	t.Run("Missing go.mod", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		commit(t, repo, map[string]string{"a.txt": "a"})
+
+		_, err = getVersion(repo, "sdk")
+		require.ErrorContains(t, err, "reading sdk/go.mod")
+	})
+}
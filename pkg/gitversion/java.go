@@ -0,0 +1,17 @@
+package gitversion
+
+import "strings"
+
+// javaVersion converts a generic version into one accepted by Maven Central and Gradle, which reject
+// build metadata. The commit hash is dropped, and a dirty work tree makes the version a snapshot,
+// e.g. `1.2.0-alpha.1697040000+abcdef12.dirty` becomes `1.2.0-alpha.1697040000-SNAPSHOT`. Snapshots
+// sort before the release they precede in Maven ordering.
+func javaVersion(version string) string {
+	version, build, _ := strings.Cut(version, "+")
+	for _, identifier := range strings.Split(build, ".") {
+		if identifier == "dirty" {
+			return version + "-SNAPSHOT"
+		}
+	}
+	return version
+}
@@ -0,0 +1,26 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJavaVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.0.0":                                  "1.0.0",
+		"1.0.0-beta.2":                           "1.0.0-beta.2",
+		"1.2.0-alpha.1697040000+9fa804e8":        "1.2.0-alpha.1697040000",
+		"1.2.0-alpha.1697040000+9fa804e8.dirty":  "1.2.0-alpha.1697040000-SNAPSHOT",
+		"1.2.0+dirty":                            "1.2.0-SNAPSHOT",
+		"1.2.0+build.5":                          "1.2.0",
+		"1.2.0-alpha.1697040000+dirtyfeed.stuff": "1.2.0-alpha.1697040000",
+	}
+	for version, expected := range tests {
+		require.Equal(t, expected, javaVersion(version), version)
+	}
+
+	versions, err := GetLanguageOptionsFromVersion("v1.1.0-alpha.0+9fa804e8.dirty")
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0-alpha.0-SNAPSHOT", versions.Java)
+}
@@ -0,0 +1,118 @@
+package gitversion
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// GetModuleVersionsWithOptions calculates the versions of every module in a repository which tags
+// releases as "module/vX.Y.Z", keyed by module path as returned by ModuleTagPrefix. The root module,
+// tagged "vX.Y.Z", has an empty path. History is walked once for all modules, and each module gets
+// the version GetVersionDetailsWithOptions would calculate with a tag filter for just that module.
+//
+// If no module tags are found, the version of the root module is calculated as for a repository
+// with no tags.
+func GetModuleVersionsWithOptions(opts LanguageVersionsOptions) (map[string]*VersionDetails, error) {
+	if opts.BaseStrategy != BaseStrategyPreorder {
+		return nil, fmt.Errorf("base strategy %q is not supported for multiple modules", opts.BaseStrategy)
+	}
+
+	opts, commit, err := resolveCommit(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := newTagIndex(opts.Repo, opts.tagSelector())
+	if err != nil {
+		return nil, err
+	}
+
+	explainTags(opts, tags)
+
+	moduleTags, err := tags.moduleTags(opts.Repo, commit, opts.FirstParent)
+	if err != nil {
+		return nil, fmt.Errorf("finding module tags: %w", err)
+	}
+	if len(moduleTags) == 0 {
+		details, err := GetVersionDetailsWithOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*VersionDetails{"": details}, nil
+	}
+
+	dirtyFiles, err := dirtyFilesForOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	explainDirtyFiles(opts, dirtyFiles)
+
+	versions := map[string]*VersionDetails{}
+	// Calculate the versions in a stable order, so that explanations are reproducible
+	names := make([]string, 0, len(moduleTags))
+	for module := range moduleTags {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+
+	for _, module := range names {
+		tag := moduleTags[module]
+		isExact := false
+		for _, ref := range tags.byCommit[commit.Hash] {
+			isExact = isExact || ref.Name() == tag.Name()
+		}
+		opts.explainf("module %q: base tag %s (exact: %t)", module, tag.Name().Short(), isExact)
+
+		components, err := componentsFromBase(opts, commit, StripModuleTagPrefixes(tag.Name().Short()),
+			tag, isExact, dirtyFiles)
+		if err != nil {
+			return nil, fmt.Errorf("module %q: %w", module, err)
+		}
+
+		if versions[module], err = detailsFromComponents(opts, components); err != nil {
+			return nil, fmt.Errorf("module %q: %w", module, err)
+		}
+	}
+
+	return versions, nil
+}
+
+// moduleTags walks the history of `head` once and returns the first tag found for each module,
+// keyed by module path.
+func (idx *tagIndex) moduleTags(repo *git.Repository, head *object.Commit,
+	firstParent bool) (map[string]*plumbing.Reference, error) {
+	modules := map[string]bool{}
+	for _, refs := range idx.byCommit {
+		for _, ref := range refs {
+			modules[ModuleTagPrefix(ref.Name().Short())] = true
+		}
+	}
+
+	found := map[string]*plumbing.Reference{}
+	visit := func(commit *object.Commit) error {
+		for _, ref := range idx.byCommit[commit.Hash] {
+			module := ModuleTagPrefix(ref.Name().Short())
+			if _, ok := found[module]; !ok {
+				found[module] = ref
+			}
+		}
+		if len(found) == len(modules) {
+			return storer.ErrStop
+		}
+		return nil
+	}
+
+	if len(modules) == 0 {
+		return found, nil
+	}
+
+	if err := idx.walk(head, firstParent, visit); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
@@ -0,0 +1,77 @@
+package gitversion
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetModuleVersions(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0", "sdk/v1.0.0", "pkg/v1.2.0", "sdk/v1.1.0", "latest"})
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "after.txt", "after")
+	_, err = workTree.Commit("After the tags", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	opts := LanguageVersionsOptions{
+		Repo:           repo,
+		Commitish:      plumbing.Revision("HEAD"),
+		OmitCommitHash: true,
+	}
+	versions, err := GetModuleVersionsWithOptions(opts)
+	require.NoError(t, err)
+
+	require.Len(t, versions, 3)
+	require.Equal(t, "1.1.0-alpha.0", versions[""].SemVer)
+	require.Equal(t, "v1.0.0", versions[""].BaseTag)
+	require.Equal(t, "1.2.0-alpha.0", versions["sdk"].SemVer)
+	require.Equal(t, "sdk/v1.1.0", versions["sdk"].BaseTag)
+	require.Equal(t, "1.3.0-alpha.0", versions["pkg"].SemVer)
+	require.Equal(t, "pkg/v1.2.0", versions["pkg"].BaseTag)
+
+	t.Run("Matches a tag pattern per module", func(t *testing.T) {
+		for module, pattern := range map[string]string{"": "^v", "sdk": "^sdk/", "pkg": "^pkg/"} {
+			re := regexp.MustCompile(pattern)
+			opts := opts
+			opts.TagFilter = re.MatchString
+			expected, err := GetVersionDetailsWithOptions(opts)
+			require.NoError(t, err)
+			require.Equal(t, expected, versions[module], module)
+		}
+	})
+
+	t.Run("Exact tag", func(t *testing.T) {
+		opts := opts
+		opts.Commitish = plumbing.Revision("sdk/v1.1.0")
+		versions, err := GetModuleVersionsWithOptions(opts)
+		require.NoError(t, err)
+		require.True(t, versions["sdk"].IsExact)
+		require.Equal(t, "1.1.0", versions["sdk"].SemVer)
+		require.False(t, versions["pkg"].IsExact)
+		require.Equal(t, "1.3.0-alpha.0", versions["pkg"].SemVer)
+	})
+
+	t.Run("No tags", func(t *testing.T) {
+		repo, err := testRepoCreate()
+		require.NoError(t, err)
+		_, err = testRepoSingleCommit(repo)
+		require.NoError(t, err)
+
+		versions, err := GetModuleVersionsWithOptions(LanguageVersionsOptions{
+			Repo:           repo,
+			Commitish:      plumbing.Revision("HEAD"),
+			OmitCommitHash: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, "0.0.1-alpha.0", versions[""].SemVer)
+	})
+}
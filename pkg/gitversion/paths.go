@@ -0,0 +1,126 @@
+package gitversion
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// checkGlob returns an error if the doublestar glob `pattern` is malformed. doublestar only reports
+// bad patterns when it gets as far as the bad part while matching, whereas path.Match checks the
+// whole pattern and accepts a superset of the same syntax.
+func checkGlob(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// pathFilter restricts version calculation to the files under a set of slash-separated paths,
+// relative to the root of the repository. A nil filter matches every file.
+type pathFilter []string
+
+// newPathFilter cleans the user supplied `paths` into a pathFilter. If any of them is the root of
+// the repository the filter matches every file, and so is nil.
+func newPathFilter(paths []string) pathFilter {
+	var filter pathFilter
+	for _, p := range paths {
+		p = path.Clean(filepath.ToSlash(p))
+		p = strings.TrimPrefix(p, "/")
+		if p == "." || p == "" {
+			return nil
+		}
+		filter = append(filter, p)
+	}
+	return filter
+}
+
+// matches returns whether the file `name` is, or is under, one of the paths in the filter.
+func (f pathFilter) matches(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, p := range f {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// touchedBy returns whether `commit` changed any file under the paths in the filter, compared to
+// its first parent. Root commits touch every path which exists in them. Commits whose parent is
+// missing from a shallow clone are assumed to touch every path.
+func (f pathFilter) touchedBy(commit *object.Commit, boundary []plumbing.Hash) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, fmt.Errorf("tree of %q: %w", commit.Hash, err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		if hashIn(commit.ParentHashes[0], boundary) {
+			return true, nil
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return false, fmt.Errorf("parent of %q: %w", commit.Hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return false, fmt.Errorf("tree of %q: %w", parent.Hash, err)
+		}
+	}
+
+	// Comparing the hashes of the entries is enough, as the hash of a directory changes if and only
+	// if something under it changes.
+	for _, p := range f {
+		if entryHash(tree, p) != entryHash(parentTree, p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// entryHash returns the hash of the file or directory at `p` in `tree`, or the zero hash if there
+// is no such entry.
+func entryHash(tree *object.Tree, p string) plumbing.Hash {
+	if tree == nil {
+		return plumbing.ZeroHash
+	}
+	entry, err := tree.FindEntry(p)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
+
+// commitsTouching returns the commits in `commits` which touch the paths in `filter`.
+func commitsTouching(repo *git.Repository, commits []*object.Commit, filter pathFilter) ([]*object.Commit, error) {
+	if filter == nil {
+		return commits, nil
+	}
+
+	boundary, err := shallowBoundary(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var touching []*object.Commit
+	for _, commit := range commits {
+		touched, err := filter.touchedBy(commit, boundary)
+		if err != nil {
+			return nil, err
+		}
+		if touched {
+			touching = append(touching, commit)
+		}
+	}
+	return touching, nil
+}
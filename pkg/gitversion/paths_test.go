@@ -0,0 +1,80 @@
+package gitversion
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFilter(t *testing.T) {
+	require.Nil(t, newPathFilter(nil))
+	require.Nil(t, newPathFilter([]string{"sdk", "."}))
+	require.Nil(t, newPathFilter([]string{"./"}))
+
+	filter := newPathFilter([]string{"./sdk/", "provider/cmd"})
+	require.Equal(t, pathFilter{"sdk", "provider/cmd"}, filter)
+	require.True(t, filter.matches("sdk"))
+	require.True(t, filter.matches("sdk/go/main.go"))
+	require.True(t, filter.matches("provider/cmd/main.go"))
+	require.False(t, filter.matches("sdkx/main.go"))
+	require.False(t, filter.matches("provider/main.go"))
+}
+
+func TestGetVersionPaths(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	addFile(t, workTree, "a/file.txt", "a")
+	addFile(t, workTree, "b/file.txt", "b")
+	_, err = workTree.Commit("Add components", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+	require.NoError(t, err)
+
+	addFile(t, workTree, "b/file.txt", "b changed")
+	_, err = workTree.Commit("Change b", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+	addFile(t, workTree, "c.txt", "c")
+	_, err = workTree.Commit("Add c", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	versionFor := func(t *testing.T, paths ...string) *VersionDetails {
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:             repo,
+			Commitish:        plumbing.Revision("HEAD"),
+			OmitCommitHash:   true,
+			PreReleaseNumber: PreReleaseNumberDistance,
+			Paths:            paths,
+		})
+		require.NoError(t, err)
+		return details
+	}
+
+	t.Run("Unchanged path reports the tagged version", func(t *testing.T) {
+		details := versionFor(t, "a")
+		require.Equal(t, "1.0.0", details.SemVer)
+		require.True(t, details.IsExact)
+		require.Equal(t, "v1.0.0", details.BaseTag)
+	})
+
+	t.Run("Changed path moves past the tag", func(t *testing.T) {
+		details := versionFor(t, "b")
+		require.Equal(t, "1.1.0-alpha.1", details.SemVer)
+		require.False(t, details.IsExact)
+	})
+
+	t.Run("Without paths every commit counts", func(t *testing.T) {
+		require.Equal(t, "1.1.0-alpha.2", versionFor(t).SemVer)
+		require.Equal(t, "1.1.0-alpha.2", versionFor(t, "a", ".").SemVer)
+	})
+
+	t.Run("Only changes under the paths make the work tree dirty", func(t *testing.T) {
+		require.NoError(t, writeFile(workTree.Filesystem, "b/file.txt", "b uncommitted"))
+		require.False(t, versionFor(t, "a").Dirty)
+		require.True(t, versionFor(t, "b").Dirty)
+	})
+}
@@ -0,0 +1,139 @@
+package gitversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pep440Re matches the public version scheme in PEP440 (https://peps.python.org/pep-0440/),
+// including the alternative spellings which tools normalise. The "d" pre-release is not part of
+// PEP440, but GetLanguageOptionsFromVersion used to produce it for `-dev` versions.
+var pep440Re = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>\d+)!)?` +
+	`(?P<release>\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(?P<pre>alpha|a|beta|b|preview|pre|rc|c|d)[-_.]?(?P<preN>\d+)?)?` +
+	`(?:-(?P<postImplicit>\d+)|[-_.]?(?P<post>post|rev|r)[-_.]?(?P<postN>\d+)?)?` +
+	`(?:[-_.]?(?P<dev>dev)[-_.]?(?P<devN>\d+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pep440Version holds the parts of a PEP440 version, normalised as described by the spec.
+type pep440Version struct {
+	Epoch   string
+	Release []string
+	// Pre is "a", "b", "rc" or "d", or empty for versions which are not pre-releases.
+	Pre     string
+	PreN    string
+	HasPost bool
+	PostN   string
+	HasDev  bool
+	DevN    string
+	Local   []string
+}
+
+// parsePEP440 parses a Python version in any of the forms allowed by PEP440.
+func parsePEP440(version string) (*pep440Version, error) {
+	match := pep440Re.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return nil, fmt.Errorf("%q is not a valid PEP440 version", version)
+	}
+	group := func(name string) string {
+		return strings.ToLower(match[pep440Re.SubexpIndex(name)])
+	}
+	// Numbers are normalised without leading zeros, and implicitly 0 when left out, e.g. "1.0a"
+	number := func(n string) string {
+		if n = strings.TrimLeft(n, "0"); n == "" {
+			return "0"
+		}
+		return n
+	}
+
+	v := &pep440Version{
+		Epoch:   group("epoch"),
+		Release: strings.Split(group("release"), "."),
+	}
+	if v.Epoch != "" {
+		v.Epoch = number(v.Epoch)
+	}
+	for i, part := range v.Release {
+		v.Release[i] = number(part)
+	}
+
+	switch group("pre") {
+	case "":
+	case "a", "alpha":
+		v.Pre = "a"
+	case "b", "beta":
+		v.Pre = "b"
+	case "rc", "c", "pre", "preview":
+		v.Pre = "rc"
+	case "d":
+		v.Pre = "d"
+	}
+	if v.Pre != "" {
+		v.PreN = number(group("preN"))
+	}
+
+	if implicit := group("postImplicit"); implicit != "" {
+		v.HasPost, v.PostN = true, number(implicit)
+	} else if group("post") != "" {
+		v.HasPost, v.PostN = true, number(group("postN"))
+	}
+	if group("dev") != "" {
+		v.HasDev, v.DevN = true, number(group("devN"))
+	}
+	if local := group("local"); local != "" {
+		v.Local = strings.FieldsFunc(local, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	}
+	return v, nil
+}
+
+// semver converts the Python version into the generic version it was produced from by
+// GetLanguageOptionsFromVersion or GetLanguageVersionsWithOptions. Commit hashes are not kept in
+// Python versions so cannot be recovered, and versions using parts of PEP440 without an equivalent
+// in those conversions, such as epochs or post-releases of releases, are rejected.
+func (v *pep440Version) semver() (string, error) {
+	if v.Epoch != "" && v.Epoch != "0" {
+		return "", fmt.Errorf("version epochs have no semver equivalent")
+	}
+	release := append([]string{}, v.Release...)
+	if len(release) > 3 {
+		return "", fmt.Errorf("release %q has more than three parts", strings.Join(release, "."))
+	}
+	for len(release) < 3 {
+		release = append(release, "0")
+	}
+	version := strings.Join(release, ".")
+
+	var pre []string
+	switch v.Pre {
+	case "a":
+		pre = []string{"alpha", v.PreN}
+	case "b":
+		pre = []string{"beta", v.PreN}
+	case "rc":
+		pre = []string{"rc", v.PreN}
+	case "d":
+		pre = []string{"dev", v.PreN}
+	}
+	if v.HasPost {
+		// A post-release of a pre-release holds the timestamp from PreReleaseNumberDistanceTimestamp
+		if pre == nil {
+			return "", fmt.Errorf("post-releases have no semver equivalent")
+		}
+		pre = append(pre, v.PostN)
+	}
+	if v.HasDev {
+		if pre != nil {
+			return "", fmt.Errorf("developmental releases of pre-releases have no semver equivalent")
+		}
+		pre = []string{"dev", v.DevN}
+	}
+	if pre != nil {
+		version += "-" + strings.Join(pre, ".")
+	}
+	if len(v.Local) > 0 {
+		version += "+" + strings.Join(v.Local, ".")
+	}
+	return version, nil
+}
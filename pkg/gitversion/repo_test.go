@@ -11,6 +11,7 @@ import (
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/go-git/go-git/v5/storage/memory"
@@ -36,7 +37,7 @@ func testRepoCreate() (*git.Repository, error) {
 
 func testRepoFSCreate(baseDir string) (*git.Repository, error) {
 	gitDir := osfs.New(filepath.Join(baseDir, ".git"))
-	return git.Init(filesystem.NewStorageWithOptions(gitDir, nil, filesystem.Options{
+	return git.Init(filesystem.NewStorageWithOptions(gitDir, cache.NewObjectLRUDefault(), filesystem.Options{
 		ExclusiveAccess: true,
 	}), osfs.New(baseDir))
 }
@@ -187,3 +188,126 @@ func writeFile(workDir billy.Filesystem, fileName string, content string) error
 
 	return nil
 }
+
+// testRepoSynthetic creates a repository with `commits` commits written directly to storage, which
+// is much faster than going through the worktree. Every `tagEvery`th commit is tagged with an
+// increasing version, alternating between lightweight and annotated tags, and every tenth commit
+// merges a side commit. The last `untagged` commits are left untagged, and the current branch
+// points at the final commit.
+func testRepoSynthetic(commits, tagEvery, untagged int) (*git.Repository, error) {
+	repo, err := testRepoCreate()
+	if err != nil {
+		return nil, err
+	}
+
+	treeObj := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(treeObj); err != nil {
+		return nil, fmt.Errorf("encode tree: %w", err)
+	}
+	tree, err := repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		return nil, fmt.Errorf("store tree: %w", err)
+	}
+
+	writeCommit := func(message string, parents ...plumbing.Hash) (plumbing.Hash, error) {
+		commit := &object.Commit{
+			Author:       *testSignature,
+			Committer:    *testSignature,
+			Message:      message,
+			TreeHash:     tree,
+			ParentHashes: parents,
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := commit.Encode(obj); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("encode commit: %w", err)
+		}
+		return repo.Storer.SetEncodedObject(obj)
+	}
+
+	var head plumbing.Hash
+	tagged := 0
+	for i := 0; i < commits; i++ {
+		var parents []plumbing.Hash
+		if i > 0 {
+			parents = append(parents, head)
+		}
+		if i > 0 && i%10 == 0 {
+			side, err := writeCommit(fmt.Sprintf("side %d", i), head)
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, side)
+		}
+
+		head, err = writeCommit(fmt.Sprintf("commit %d", i), parents...)
+		if err != nil {
+			return nil, err
+		}
+
+		if i%tagEvery != 0 || i >= commits-untagged {
+			continue
+		}
+		tagged++
+		tag := fmt.Sprintf("v1.%d.0", tagged)
+		var opts *git.CreateTagOptions
+		if tagged%2 == 0 {
+			opts = &git.CreateTagOptions{Message: tag, Tagger: testSignature}
+		}
+		if _, err := repo.CreateTag(tag, head, opts); err != nil {
+			return nil, fmt.Errorf("tag: %w", err)
+		}
+	}
+
+	branch := plumbing.NewHashReference(plumbing.Master, head)
+	if err := repo.Storer.SetReference(branch); err != nil {
+		return nil, fmt.Errorf("set branch: %w", err)
+	}
+
+	return repo, nil
+}
+
+// testRepoMergedReleaseBranch creates the following history, where `v1.0.1` is a fix on a release
+// branch which has been merged back into the main branch. If `releaseFirst` is true, the release
+// branch is the first parent of the merge commit.
+//
+//	v1.0.0 --- main ----- merge (HEAD)
+//	      \              /
+//	       `-- v1.0.1 --'
+func testRepoMergedReleaseBranch(t *testing.T, releaseFirst bool) *git.Repository {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	release := head.Hash()
+
+	addFile(t, workTree, "main.txt", "main")
+	main, err := workTree.Commit("Main commit", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	addFile(t, workTree, "fix.txt", "fix")
+	fix, err := workTree.Commit("Release fix", &git.CommitOptions{
+		Author:  testSignature,
+		Parents: []plumbing.Hash{release},
+	})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.1", fix, nil)
+	require.NoError(t, err)
+
+	parents := []plumbing.Hash{main, fix}
+	if releaseFirst {
+		parents = []plumbing.Hash{fix, main}
+	}
+	_, err = workTree.Commit("Merge release branch", &git.CommitOptions{
+		Author:            testSignature,
+		Parents:           parents,
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+
+	return repo
+}
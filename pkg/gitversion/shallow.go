@@ -0,0 +1,127 @@
+package gitversion
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ShallowPolicy controls how the version is calculated when the repository is a shallow clone, such
+// as a CI checkout with `fetch-depth: 1`, and no tag can be found in the history that was fetched.
+type ShallowPolicy string
+
+const (
+	// ShallowPolicyIgnore calculates the version from "0.0.0", as for a repository with no tags. This is
+	// the default, which is how versions were always calculated for shallow clones.
+	ShallowPolicyIgnore ShallowPolicy = ""
+	// ShallowPolicyError fails with an error, rather than calculating a version from "0.0.0".
+	ShallowPolicyError ShallowPolicy = "error"
+	// ShallowPolicyFallback bases the version on the highest tag listed by
+	// LanguageVersionsOptions.ShallowFallback.
+	ShallowPolicyFallback ShallowPolicy = "fallback"
+	// ShallowPolicyFetch runs `git fetch --unshallow --tags` before calculating the version.
+	ShallowPolicyFetch ShallowPolicy = "fetch"
+)
+
+// ParseShallowPolicy converts a user supplied policy name into a ShallowPolicy.
+func ParseShallowPolicy(name string) (ShallowPolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "ignore":
+		return ShallowPolicyIgnore, nil
+	case "error":
+		return ShallowPolicyError, nil
+	case "fallback":
+		return ShallowPolicyFallback, nil
+	case "fetch":
+		return ShallowPolicyFetch, nil
+	default:
+		return "", fmt.Errorf("invalid shallow policy %q", name)
+	}
+}
+
+// shallowBoundary returns the parents of the shallow commits in `repo`, which are missing from a
+// shallow clone. History walks ignore these so that they stop at the shallow commits rather than
+// failing to load their parents. The result is empty if `repo` is not a shallow clone.
+func shallowBoundary(repo *git.Repository) ([]plumbing.Hash, error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("reading shallow commits: %w", err)
+	}
+
+	var boundary []plumbing.Hash
+	for _, hash := range shallow {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("no commit for shallow commit %q: %w", hash, err)
+		}
+		boundary = append(boundary, commit.ParentHashes...)
+	}
+	return boundary, nil
+}
+
+// unshallow fetches the full history and tags of `repo` and reopens it, so that the new objects are
+// visible. Only repositories on disk can be unshallowed.
+func unshallow(repo *git.Repository) (*git.Repository, error) {
+	if _, ok := repo.Storer.(*filesystem.Storage); !ok {
+		return nil, fmt.Errorf("cannot fetch history for a repository which is not on disk")
+	}
+
+	workTree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("looking up worktree: %w", err)
+	}
+
+	c := exec.Command("git", "fetch", "--unshallow", "--tags")
+	c.Dir = workTree.Filesystem.Root()
+	if output, err := c.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git fetch --unshallow: %w: %s", err, output)
+	}
+
+	return git.PlainOpenWithOptions(c.Dir, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true})
+}
+
+// fallbackBaseVersion returns the highest version from the tags listed by `opts.ShallowFallback`
+// which pass the same filters as the tags in the repository. The returned reference has a zero
+// hash, as the tag is not in the repository.
+func fallbackBaseVersion(opts LanguageVersionsOptions) (string, *plumbing.Reference, error) {
+	if opts.ShallowFallback == nil {
+		return "", nil, fmt.Errorf("no fallback was provided for the base version of a shallow clone")
+	}
+
+	tags, err := opts.ShallowFallback()
+	if err != nil {
+		return "", nil, fmt.Errorf("listing fallback tags: %w", err)
+	}
+
+	selector := opts.tagSelector()
+	var highest *semver.Version
+	var highestTag string
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(tag, "refs/tags/")
+		if selector.exclusion(plumbing.NewTagReferenceName(tag)) != "" {
+			continue
+		}
+
+		version, err := semver.Parse(StripModuleTagPrefixes(tag))
+		if err != nil {
+			continue
+		}
+		if highest == nil || version.GT(*highest) {
+			highest = &version
+			highestTag = tag
+		}
+	}
+
+	if highest == nil {
+		return "", nil, fmt.Errorf("no fallback tags matched for the base version of a shallow clone")
+	}
+	ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(highestTag), plumbing.ZeroHash)
+	return highest.String(), ref, nil
+}
@@ -0,0 +1,107 @@
+package gitversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepoShallow creates a repository tagged `v1.0.0` followed by two more commits, and marks the
+// last commit as shallow, as if the repository was cloned with a depth of 1.
+func testRepoShallow(t *testing.T) *git.Repository {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+	require.NoError(t, err)
+
+	var head plumbing.Hash
+	for _, name := range []string{"a.txt", "b.txt"} {
+		addFile(t, workTree, name, name)
+		head, err = workTree.Commit(name, &git.CommitOptions{Author: testSignature})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.Storer.SetShallow([]plumbing.Hash{head}))
+	return repo
+}
+
+func TestShallowClone(t *testing.T) {
+	getVersion := func(repo *git.Repository, policy ShallowPolicy,
+		fallback func() ([]string, error)) (*VersionDetails, error) {
+		return GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:            repo,
+			Commitish:       plumbing.Revision("HEAD"),
+			OmitCommitHash:  true,
+			OnShallow:       policy,
+			ShallowFallback: fallback,
+			TagFilter: func(tag string) bool {
+				return !strings.Contains(tag, "/")
+			},
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := getVersion(testRepoShallow(t), ShallowPolicyError, nil)
+		require.ErrorContains(t, err, "shallow clone")
+	})
+
+	t.Run("Ignore by default", func(t *testing.T) {
+		var policy ShallowPolicy
+		require.Equal(t, ShallowPolicyIgnore, policy)
+
+		details, err := getVersion(testRepoShallow(t), policy, nil)
+		require.NoError(t, err)
+		require.Equal(t, "0.0.1-alpha.0", details.SemVer)
+		require.Empty(t, details.BaseTag)
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		fallback := func() ([]string, error) {
+			return []string{"v0.9.0", "refs/tags/v1.0.0", "v2.0.0-beta.1", "sdk/v3.0.0", "latest"}, nil
+		}
+		details, err := getVersion(testRepoShallow(t), ShallowPolicyFallback, fallback)
+		require.NoError(t, err)
+		require.Equal(t, "1.1.0-alpha.0", details.SemVer)
+		require.Equal(t, "v1.0.0", details.BaseTag)
+	})
+
+	t.Run("Fallback without a source", func(t *testing.T) {
+		_, err := getVersion(testRepoShallow(t), ShallowPolicyFallback, nil)
+		require.ErrorContains(t, err, "no fallback")
+	})
+
+	t.Run("Fallback with distance", func(t *testing.T) {
+		fallback := func() ([]string, error) {
+			return []string{"v1.0.0"}, nil
+		}
+		details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+			Repo:             testRepoShallow(t),
+			Commitish:        plumbing.Revision("HEAD"),
+			OmitCommitHash:   true,
+			OnShallow:        ShallowPolicyFallback,
+			ShallowFallback:  fallback,
+			PreReleaseNumber: PreReleaseNumberDistance,
+		})
+		require.NoError(t, err)
+		// Only the shallow commit itself is available to count.
+		require.Equal(t, "1.1.0-alpha.1", details.SemVer)
+	})
+
+	t.Run("Exact tag in a shallow clone", func(t *testing.T) {
+		repo := testRepoShallow(t)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		_, err = repo.CreateTag("v1.0.1", head.Hash(), nil)
+		require.NoError(t, err)
+
+		details, err := getVersion(repo, ShallowPolicyError, nil)
+		require.NoError(t, err)
+		require.Equal(t, "1.0.1", details.SemVer)
+	})
+}
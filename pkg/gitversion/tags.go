@@ -0,0 +1,523 @@
+package gitversion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// BaseStrategy controls which tag the version is based on when the commit being versioned is not
+// tagged itself.
+type BaseStrategy string
+
+const (
+	// BaseStrategyPreorder walks history in pre-order and picks the first tagged commit found. After
+	// merges this can be an older tag than the nearest one.
+	BaseStrategyPreorder BaseStrategy = ""
+	// BaseStrategyNearest picks the tag with the fewest commits between it and the commit being
+	// versioned, matching `git describe`.
+	BaseStrategyNearest BaseStrategy = "nearest"
+	// BaseStrategyHighestReachable picks the tag with the highest version reachable from the commit
+	// being versioned, wherever it is in history. This suits repositories which cherry-pick fixes
+	// onto release branches, where the nearest tag may be an older release.
+	BaseStrategyHighestReachable BaseStrategy = "highest-reachable"
+)
+
+// ParseBaseStrategy converts a user supplied strategy name into a BaseStrategy.
+func ParseBaseStrategy(name string) (BaseStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", "preorder":
+		return BaseStrategyPreorder, nil
+	case "nearest":
+		return BaseStrategyNearest, nil
+	case "highest-reachable":
+		return BaseStrategyHighestReachable, nil
+	default:
+		return "", fmt.Errorf("invalid base strategy %q", name)
+	}
+}
+
+// tagIndex maps commit hashes to the tags pointing at them. It is built once per version
+// calculation so that walking history only costs a map lookup per commit, rather than a scan of
+// every tag in the repository.
+type tagIndex struct {
+	// byCommit holds the tags for each commit in the order the repository listed them. Annotated
+	// tags are peeled, so they are keyed by the commit they point at rather than the tag object.
+	byCommit map[plumbing.Hash][]*plumbing.Reference
+
+	// boundary holds the commits missing from a shallow clone, at which history walks stop.
+	boundary []plumbing.Hash
+
+	// considered holds the indexed tags, and skipped the tags which were not indexed. They are only
+	// used to explain the version calculation.
+	considered []*plumbing.Reference
+	skipped    []skippedTag
+}
+
+// skippedTag records a tag which is not a candidate for version calculation, and why.
+type skippedTag struct {
+	name   string
+	reason string
+}
+
+// isShallow returns whether the indexed repository is a shallow clone.
+func (idx *tagIndex) isShallow() bool {
+	return len(idx.boundary) > 0
+}
+
+// newTagIndex lists the tags in `repo` which are candidates for version calculation and indexes
+// them by commit.
+func newTagIndex(repo *git.Repository, selector tagSelector) (*tagIndex, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags: %w", err)
+	}
+
+	index := &tagIndex{byCommit: map[plumbing.Hash][]*plumbing.Reference{}}
+	if err := tags.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			// Skip symbolic refs, for simplicity. We're not going to try and recursively resolve these.
+			index.skipped = append(index.skipped, skippedTag{ref.Name().Short(), "symbolic reference"})
+			return nil
+		}
+
+		if reason := selector.exclusion(ref.Name()); reason != "" {
+			index.skipped = append(index.skipped, skippedTag{ref.Name().Short(), reason})
+			return nil
+		}
+
+		target, err := peelTag(repo, ref)
+		if err != nil {
+			return err
+		}
+		index.byCommit[target] = append(index.byCommit[target], ref)
+		index.considered = append(index.considered, ref)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("error iterating on tags: %w", err)
+	}
+
+	if index.boundary, err = shallowBoundary(repo); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// TagPolicy controls whether tags with a given prerelease identifier, such as "beta" in
+// v1.0.0-beta.1, are candidates to base versions on.
+type TagPolicy string
+
+const (
+	// TagPolicyRelease makes the tags candidates for every version, as with release tags.
+	TagPolicyRelease TagPolicy = "release"
+	// TagPolicyPreRelease makes the tags candidates only when calculating prerelease versions.
+	TagPolicyPreRelease TagPolicy = "prerelease"
+	// TagPolicyNever ignores the tags.
+	TagPolicyNever TagPolicy = "never"
+)
+
+// ParseTagPolicy converts a user supplied policy name into a TagPolicy.
+func ParseTagPolicy(name string) (TagPolicy, error) {
+	switch strings.ToLower(name) {
+	case "release":
+		return TagPolicyRelease, nil
+	case "prerelease":
+		return TagPolicyPreRelease, nil
+	case "never":
+		return TagPolicyNever, nil
+	default:
+		return "", fmt.Errorf("invalid tag policy %q", name)
+	}
+}
+
+// ParseTagPolicies converts user supplied "identifier=policy" pairs, e.g. "beta=never", into
+// policies for LanguageVersionsOptions.PreReleaseTagPolicies.
+func ParseTagPolicies(specs []string) (map[string]TagPolicy, error) {
+	policies := map[string]TagPolicy{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag policy %q, expected <identifier>=<policy>", spec)
+		}
+		policy, err := ParseTagPolicy(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		policies[parts[0]] = policy
+	}
+	return policies, nil
+}
+
+// DefaultPreReleaseTagPolicies returns the policies for prerelease identifiers which are not
+// configured otherwise. Beta and release candidate tags are usually for the next major version, so
+// they are only used to calculate prereleases. Other identifiers, such as "alpha", are treated as
+// releases.
+func DefaultPreReleaseTagPolicies() map[string]TagPolicy {
+	return map[string]TagPolicy{
+		"beta": TagPolicyPreRelease,
+		"rc":   TagPolicyPreRelease,
+	}
+}
+
+// tagSelector decides which tags are candidates for version calculation.
+type tagSelector struct {
+	isPrerelease bool
+	// filter, if set, only accepts the tag names which match, e.g. those with an "sdk/" prefix.
+	filter func(string) bool
+	// policies overrides DefaultPreReleaseTagPolicies by prerelease identifier. The "*" identifier
+	// matches any identifier without a policy of its own.
+	policies map[string]TagPolicy
+}
+
+// tagSelector returns the tagSelector for the tag options in `opts`.
+func (opts LanguageVersionsOptions) tagSelector() tagSelector {
+	return tagSelector{
+		isPrerelease: opts.IsPreRelease,
+		filter:       opts.TagFilter,
+		policies:     opts.PreReleaseTagPolicies,
+	}
+}
+
+// policy returns the TagPolicy for tags with the prerelease `identifier`.
+func (s tagSelector) policy(identifier string) TagPolicy {
+	if policy, ok := s.policies[identifier]; ok {
+		return policy
+	}
+	if policy, ok := s.policies["*"]; ok {
+		return policy
+	}
+	if policy, ok := DefaultPreReleaseTagPolicies()[identifier]; ok {
+		return policy
+	}
+	return TagPolicyRelease
+}
+
+// exclusion returns why the tag `refName` is not a candidate for version calculation, or "" if it
+// is one.
+func (s tagSelector) exclusion(refName plumbing.ReferenceName) string {
+	name := refName.Short()
+
+	// if tagFilter such as "sdk/" prefix is specified, we
+	// only consider refs that match.
+	if s.filter != nil && !s.filter(name) {
+		return "does not match the tag filter"
+	}
+
+	version, err := semver.Parse(StripModuleTagPrefixes(name))
+	if err != nil {
+		return "not a valid version"
+	}
+	if len(version.Pre) == 0 {
+		return ""
+	}
+
+	// Prerelease tags, such as betas for the next major version, may not be wanted in the
+	// calculation of the current release variant
+	identifier := preReleaseIdentifier(version.Pre[0])
+	switch s.policy(identifier) {
+	case TagPolicyNever:
+		return fmt.Sprintf("prerelease %q is never a candidate", identifier)
+	case TagPolicyPreRelease:
+		if !s.isPrerelease {
+			return fmt.Sprintf("prerelease %q is only a candidate for prereleases", identifier)
+		}
+	}
+	return ""
+}
+
+// preReleaseIdentifier returns the identifier which policies are looked up by for a tag whose
+// prerelease starts with `pre`. Trailing digits are dropped, so "rc1" and "beta2" get the policies
+// of "rc" and "beta", as "rc.1" and "beta.2" do.
+func preReleaseIdentifier(pre semver.PRVersion) string {
+	identifier := pre.String()
+	if trimmed := strings.TrimRight(identifier, "0123456789"); trimmed != "" {
+		return trimmed
+	}
+	return identifier
+}
+
+// exactTag returns the first tag pointing at `hash`, or nil if there is none.
+func (idx *tagIndex) exactTag(hash plumbing.Hash) *plumbing.Reference {
+	if refs := idx.byCommit[hash]; len(refs) > 0 {
+		return refs[0]
+	}
+	return nil
+}
+
+// mostRecentTag walks the history of `hash` in pre-order and returns the first tag found, or nil if
+// no commit reachable from `hash` is tagged.
+func (idx *tagIndex) mostRecentTag(repo *git.Repository, hash plumbing.Hash) (*plumbing.Reference, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("no commit for ref %q: %w", hash, err)
+	}
+
+	var mostRecentTag *plumbing.Reference
+	err = object.NewCommitPreorderIter(commit, nil, idx.boundary).ForEach(func(commit *object.Commit) error {
+		if exact := idx.exactTag(commit.Hash); exact != nil {
+			mostRecentTag = exact
+			return storer.ErrStop
+		}
+		return nil
+	})
+
+	return mostRecentTag, err
+}
+
+// selectTag returns the tag to base the version of `hash` on according to `strategy`, or nil if no
+// commit reachable from `hash` is tagged. If `firstParent` is true, only the first parent of each
+// merge commit is followed, in which case the pre-order and nearest strategies both pick the first
+// tag found.
+func (idx *tagIndex) selectTag(repo *git.Repository, hash plumbing.Hash, strategy BaseStrategy,
+	firstParent bool) (*plumbing.Reference, error) {
+	if strategy == BaseStrategyHighestReachable {
+		return idx.highestTag(repo, hash, firstParent)
+	}
+	if firstParent {
+		return idx.firstParentTag(repo, hash)
+	}
+
+	switch strategy {
+	case BaseStrategyPreorder:
+		return idx.mostRecentTag(repo, hash)
+	case BaseStrategyNearest:
+		return idx.nearestTag(repo, hash)
+	default:
+		return nil, fmt.Errorf("unknown base strategy %q", strategy)
+	}
+}
+
+// firstParentTag follows the first parent of each commit from `hash` and returns the first tag found.
+func (idx *tagIndex) firstParentTag(repo *git.Repository, hash plumbing.Hash) (*plumbing.Reference, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("no commit for ref %q: %w", hash, err)
+	}
+
+	for {
+		if exact := idx.exactTag(commit.Hash); exact != nil {
+			return exact, nil
+		}
+		if commit.NumParents() == 0 || idx.inBoundary(commit.ParentHashes[0]) {
+			return nil, nil
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("parent of %q: %w", commit.Hash, err)
+		}
+		commit = parent
+	}
+}
+
+// highestTag returns the tag with the highest version on the commits reachable from `hash`, following
+// only first parents if `firstParent` is true. Ties are broken in favour of the first tag found
+// walking history in pre-order.
+func (idx *tagIndex) highestTag(repo *git.Repository, hash plumbing.Hash,
+	firstParent bool) (*plumbing.Reference, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("no commit for ref %q: %w", hash, err)
+	}
+
+	var highest *plumbing.Reference
+	var highestVersion semver.Version
+	err = idx.walk(commit, firstParent, func(commit *object.Commit) error {
+		for _, ref := range idx.byCommit[commit.Hash] {
+			// Only valid versions are indexed
+			version := semver.MustParse(StripModuleTagPrefixes(ref.Name().Short()))
+			if highest == nil || version.GT(highestVersion) {
+				highest = ref
+				highestVersion = version
+			}
+		}
+		return nil
+	})
+	return highest, err
+}
+
+// walk calls `visit` for `head` and each of its ancestors in pre-order, or only for its first
+// parents if `firstParent` is true, until `visit` returns an error. storer.ErrStop stops the walk
+// without an error.
+func (idx *tagIndex) walk(head *object.Commit, firstParent bool, visit func(*object.Commit) error) error {
+	if !firstParent {
+		return object.NewCommitPreorderIter(head, nil, idx.boundary).ForEach(visit)
+	}
+
+	for commit := head; ; {
+		if err := visit(commit); err != nil {
+			if err == storer.ErrStop {
+				return nil
+			}
+			return err
+		}
+		if commit.NumParents() == 0 || idx.inBoundary(commit.ParentHashes[0]) {
+			return nil
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return fmt.Errorf("parent of %q: %w", commit.Hash, err)
+		}
+		commit = parent
+	}
+}
+
+// nearestTag returns the tag with the fewest commits between it and `hash`, i.e. the smallest
+// `git rev-list --count <tag>..<hash>`. Ties are broken in favour of the most recently committed tag.
+//
+// Every ancestor of a tagged commit is also an ancestor of `hash`, so the nearest tag is the one
+// with the most ancestors. A tag behind another tag can never be nearest, so we only count the
+// ancestors of the tagged commits found without walking past another tagged commit.
+func (idx *tagIndex) nearestTag(repo *git.Repository, hash plumbing.Hash) (*plumbing.Reference, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("no commit for ref %q: %w", hash, err)
+	}
+
+	var candidates []*object.Commit
+	seen := map[plumbing.Hash]bool{}
+	stack := []*object.Commit{commit}
+	for len(stack) > 0 {
+		commit := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[commit.Hash] {
+			continue
+		}
+		seen[commit.Hash] = true
+
+		if idx.exactTag(commit.Hash) != nil {
+			candidates = append(candidates, commit)
+			continue
+		}
+		for _, hash := range commit.ParentHashes {
+			if idx.inBoundary(hash) {
+				continue
+			}
+			parent, err := repo.CommitObject(hash)
+			if err != nil {
+				return nil, fmt.Errorf("parent of %q: %w", commit.Hash, err)
+			}
+			stack = append(stack, parent)
+		}
+	}
+
+	var nearest *object.Commit
+	nearestAncestors := 0
+	for _, candidate := range candidates {
+		ancestors := 0
+		if err := object.NewCommitPreorderIter(candidate, nil, idx.boundary).ForEach(func(*object.Commit) error {
+			ancestors++
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		if nearest == nil || ancestors > nearestAncestors ||
+			ancestors == nearestAncestors && candidate.Committer.When.After(nearest.Committer.When) {
+			nearest = candidate
+			nearestAncestors = ancestors
+		}
+	}
+
+	if nearest == nil {
+		return nil, nil
+	}
+	return idx.exactTag(nearest.Hash), nil
+}
+
+// inBoundary returns whether `hash` is missing from a shallow clone.
+func (idx *tagIndex) inBoundary(hash plumbing.Hash) bool {
+	return hashIn(hash, idx.boundary)
+}
+
+func hashIn(hash plumbing.Hash, hashes []plumbing.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// commitsSinceTag returns the commits between `tag` and `head`. If `firstParent` is true, only the
+// first parent of each merge commit is followed. If `tag` is nil, or is not in the repository and so
+// has a zero hash, all commits reachable from `head` are returned.
+func commitsSinceTag(repo *git.Repository, head *object.Commit, tag *plumbing.Reference,
+	firstParent bool) ([]*object.Commit, error) {
+	boundary, err := shallowBoundary(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *plumbing.Hash
+	if tag != nil && !tag.Hash().IsZero() {
+		hash, err := peelTag(repo, tag)
+		if err != nil {
+			return nil, err
+		}
+		base = &hash
+	}
+
+	if !firstParent {
+		return commitsSince(repo, head, base, boundary)
+	}
+
+	var commits []*object.Commit
+	for commit := head; base == nil || commit.Hash != *base; {
+		commits = append(commits, commit)
+		if commit.NumParents() == 0 || hashIn(commit.ParentHashes[0], boundary) {
+			break
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("parent of %q: %w", commit.Hash, err)
+		}
+		commit = parent
+	}
+	return commits, nil
+}
+
+// commitsSince returns the commits reachable from `head` which are not reachable from `base`, in
+// the same way as `git rev-list base..head`. If `base` is nil, all commits reachable from `head` are
+// returned. Walks stop at the commits in `boundary`.
+func commitsSince(repo *git.Repository, head *object.Commit, base *plumbing.Hash,
+	boundary []plumbing.Hash) ([]*object.Commit, error) {
+	seen := map[plumbing.Hash]bool{}
+	if base != nil {
+		baseCommit, err := repo.CommitObject(*base)
+		if err != nil {
+			return nil, fmt.Errorf("no commit for base %q: %w", base, err)
+		}
+		if err := object.NewCommitPreorderIter(baseCommit, nil, boundary).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []*object.Commit
+	err := object.NewCommitPreorderIter(head, seen, boundary).ForEach(func(c *object.Commit) error {
+		commits = append(commits, c)
+		return nil
+	})
+	return commits, err
+}
+
+// peelTag returns the hash of the commit a tag reference points to, following annotated tags.
+func peelTag(repo *git.Repository, ref *plumbing.Reference) (plumbing.Hash, error) {
+	obj, err := repo.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		return obj.Target, nil
+	case plumbing.ErrObjectNotFound:
+		return ref.Hash(), nil
+	default:
+		return plumbing.ZeroHash, err
+	}
+}
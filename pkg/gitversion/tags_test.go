@@ -0,0 +1,220 @@
+package gitversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/stretchr/testify/require"
+)
+
+// naiveMostRecentTag is the original implementation of mostRecentTag, which rescans every tag for
+// each commit it walks. It is kept as a reference for the tag index.
+func naiveMostRecentTag(repo *git.Repository, hash plumbing.Hash,
+	isPrerelease bool, tagFilter func(string) bool) (*plumbing.Reference, error) {
+	exactTag := func(hash plumbing.Hash) (*plumbing.Reference, error) {
+		tags, err := repo.Tags()
+		if err != nil {
+			return nil, err
+		}
+
+		var exact *plumbing.Reference
+		err = tags.ForEach(func(ref *plumbing.Reference) error {
+			if ref.Type() != plumbing.HashReference {
+				return nil
+			}
+			refName := ref.Name().String()
+			if !isPrerelease && (strings.Contains(refName, "beta") || strings.Contains(refName, "rc")) {
+				return nil
+			}
+			if tagFilter != nil && !tagFilter(strings.TrimPrefix(refName, "refs/tags/")) {
+				return nil
+			}
+
+			obj, err := repo.TagObject(ref.Hash())
+			switch err {
+			case nil:
+				if obj.Target == hash {
+					exact = ref
+					return storer.ErrStop
+				}
+			case plumbing.ErrObjectNotFound:
+				if ref.Hash() == hash {
+					exact = ref
+					return storer.ErrStop
+				}
+			default:
+				return err
+			}
+			return nil
+		})
+		return exact, err
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var mostRecent *plumbing.Reference
+	err = object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(commit *object.Commit) error {
+		exact, err := exactTag(commit.Hash)
+		if err != nil {
+			return err
+		}
+		if exact != nil {
+			mostRecent = exact
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return mostRecent, err
+}
+
+func TestTagIndexMatchesNaiveScan(t *testing.T) {
+	repo, err := testRepoSynthetic(200, 7, 20)
+	require.NoError(t, err)
+
+	someTags := func(tag string) bool {
+		return !strings.HasSuffix(tag, "1.0") && !strings.HasSuffix(tag, "3.0")
+	}
+
+	for _, tagFilter := range []func(string) bool{nil, someTags} {
+		index, err := newTagIndex(repo, tagSelector{filter: tagFilter})
+		require.NoError(t, err)
+
+		commits, err := repo.CommitObjects()
+		require.NoError(t, err)
+		err = commits.ForEach(func(commit *object.Commit) error {
+			expected, err := naiveMostRecentTag(repo, commit.Hash, false, tagFilter)
+			require.NoError(t, err)
+
+			actual, err := index.mostRecentTag(repo, commit.Hash)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual, "commit %s", commit.Hash)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestTagSelector(t *testing.T) {
+	excluded := func(selector tagSelector, tag string) string {
+		return selector.exclusion(plumbing.NewTagReferenceName(tag))
+	}
+
+	release := tagSelector{}
+	require.Empty(t, excluded(release, "v1.0.0"))
+	require.Empty(t, excluded(release, "rcloud/v1.0.0"))
+	require.Empty(t, excluded(release, "pulumi-betteruptime/v1.2.0"))
+	require.Empty(t, excluded(release, "v1.0.0-alpha.1"))
+	require.Equal(t, `prerelease "beta" is only a candidate for prereleases`, excluded(release, "v1.0.0-beta.1"))
+	require.Equal(t, `prerelease "rc" is only a candidate for prereleases`, excluded(release, "sdk/v1.0.0-rc.1"))
+	require.Equal(t, "not a valid version", excluded(release, "latest"))
+	require.Equal(t, "not a valid version", excluded(release, "v1.0"))
+
+	prerelease := tagSelector{isPrerelease: true}
+	require.Empty(t, excluded(prerelease, "v1.0.0-beta.1"))
+	require.Empty(t, excluded(prerelease, "v1.0.0-rc.1"))
+
+	filtered := tagSelector{filter: func(tag string) bool { return strings.HasPrefix(tag, "sdk/") }}
+	require.Empty(t, excluded(filtered, "sdk/v1.0.0"))
+	require.Equal(t, "does not match the tag filter", excluded(filtered, "v1.0.0"))
+
+	policies, err := ParseTagPolicies([]string{"dev=never", "beta=release"})
+	require.NoError(t, err)
+	configured := tagSelector{isPrerelease: true, policies: policies}
+	require.Equal(t, `prerelease "dev" is never a candidate`, excluded(configured, "v1.0.0-dev.1"))
+	require.Empty(t, excluded(configured, "v1.0.0-beta.1"))
+	configured.isPrerelease = false
+	require.Empty(t, excluded(configured, "v1.0.0-beta.1"))
+	require.NotEmpty(t, excluded(configured, "v1.0.0-rc.1"))
+
+	onlyReleases := tagSelector{isPrerelease: true, policies: map[string]TagPolicy{"*": TagPolicyNever}}
+	require.Empty(t, excluded(onlyReleases, "v1.0.0"))
+	require.NotEmpty(t, excluded(onlyReleases, "v1.0.0-alpha.1"))
+	require.NotEmpty(t, excluded(onlyReleases, "v1.0.0-beta.1"))
+
+	// Numbers attached to the identifier don't change its policy
+	for _, tag := range []string{"v1.3.0-rc1", "v1.3.0-rc.1", "v1.3.0-rc12.1"} {
+		require.Equal(t, `prerelease "rc" is only a candidate for prereleases`, excluded(release, tag))
+	}
+	require.Equal(t, `prerelease "beta" is only a candidate for prereleases`, excluded(release, "v1.3.0-beta2"))
+	require.Empty(t, excluded(release, "v1.3.0-1"))
+
+	_, err = ParseTagPolicies([]string{"beta"})
+	require.Error(t, err)
+	_, err = ParseTagPolicies([]string{"beta=sometimes"})
+	require.Error(t, err)
+}
+
+func TestTagIndexPeelsAnnotatedTags(t *testing.T) {
+	repo, err := testRepoSynthetic(10, 1, 0)
+	require.NoError(t, err)
+
+	index, err := newTagIndex(repo, tagSelector{})
+	require.NoError(t, err)
+
+	ref, err := repo.Tag("v1.2.0")
+	require.NoError(t, err)
+	tagObj, err := repo.TagObject(ref.Hash())
+	require.NoError(t, err, "v1.2.0 should be an annotated tag")
+
+	exact := index.exactTag(tagObj.Target)
+	require.NotNil(t, exact)
+	require.Equal(t, "v1.2.0", exact.Name().Short())
+}
+
+func BenchmarkMostRecentTag(b *testing.B) {
+	repo, err := testRepoSynthetic(2000, 10, 1000)
+	require.NoError(b, err)
+
+	head, err := repo.Head()
+	require.NoError(b, err)
+
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tag, err := naiveMostRecentTag(repo, head.Hash(), false, nil)
+			require.NoError(b, err)
+			require.NotNil(b, tag)
+		}
+	})
+
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tags, err := newTagIndex(repo, tagSelector{})
+			require.NoError(b, err)
+			tag, err := tags.mostRecentTag(repo, head.Hash())
+			require.NoError(b, err)
+			require.NotNil(b, tag)
+		}
+	})
+}
+
+func TestGetVersionSkipsAttachedReleaseCandidates(t *testing.T) {
+	for _, rc := range []string{"v1.3.0-rc1", "v1.3.0-beta2", "v1.3.0-rc.1"} {
+		t.Run(rc, func(t *testing.T) {
+			repo, err := testRepoCreate()
+			require.NoError(t, err)
+			repo, err = testRepoWithTags(repo, []string{"v1.2.0", rc})
+			require.NoError(t, err)
+
+			workTree, err := repo.Worktree()
+			require.NoError(t, err)
+			addFile(t, workTree, "after.txt", "after")
+			_, err = workTree.Commit("After the release candidate", &git.CommitOptions{Author: testSignature})
+			require.NoError(t, err)
+
+			details, err := GetVersionDetailsWithOptions(LanguageVersionsOptions{
+				Repo:      repo,
+				Commitish: plumbing.Revision("HEAD"),
+			})
+			require.NoError(t, err)
+			require.Equal(t, "v1.2.0", details.BaseTag)
+			require.True(t, strings.HasPrefix(details.SemVer, "1.3.0-alpha."), details.SemVer)
+		})
+	}
+}
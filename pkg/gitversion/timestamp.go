@@ -0,0 +1,61 @@
+package gitversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TimestampSource controls which time of the commit being versioned is used as its timestamp, which
+// is the prerelease number of versions past the base tag.
+type TimestampSource string
+
+const (
+	// TimestampSourceCommitter uses the committer time, which changes when a commit is rebased or
+	// amended.
+	TimestampSourceCommitter TimestampSource = ""
+	// TimestampSourceAuthor uses the author time, which is kept when a commit is rebased.
+	TimestampSourceAuthor TimestampSource = "author"
+)
+
+// ParseTimestampSource converts a user supplied source name into a TimestampSource.
+func ParseTimestampSource(name string) (TimestampSource, error) {
+	switch strings.ToLower(name) {
+	case "", "committer":
+		return TimestampSourceCommitter, nil
+	case "author":
+		return TimestampSourceAuthor, nil
+	default:
+		return "", fmt.Errorf("invalid timestamp source %q", name)
+	}
+}
+
+// ParseTimestamp parses a timestamp given as seconds since the Unix epoch, as in
+// SOURCE_DATE_EPOCH (https://reproducible-builds.org/specs/source-date-epoch/), or in RFC 3339
+// format.
+func ParseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, expected seconds since the epoch or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// commitTimestamp returns the timestamp for `commit`, which is `opts.Timestamp` if set, otherwise
+// the time from `opts.TimestampSource`, along with where it came from.
+func commitTimestamp(opts LanguageVersionsOptions, commit *object.Commit) (time.Time, string) {
+	switch {
+	case !opts.Timestamp.IsZero():
+		return opts.Timestamp, "overridden"
+	case opts.TimestampSource == TimestampSourceAuthor:
+		return commit.Author.When, "author time"
+	default:
+		return commit.Committer.When, "committer time"
+	}
+}
@@ -0,0 +1,59 @@
+package gitversion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	timestamp, err := ParseTimestamp("1697040000")
+	require.NoError(t, err)
+	require.Equal(t, int64(1697040000), timestamp.Unix())
+
+	timestamp, err = ParseTimestamp("2023-10-11T16:00:00Z")
+	require.NoError(t, err)
+	require.Equal(t, int64(1697040000), timestamp.Unix())
+
+	_, err = ParseTimestamp("yesterday")
+	require.ErrorContains(t, err, `invalid timestamp "yesterday"`)
+}
+
+func TestGetVersionTimestamp(t *testing.T) {
+	repo, err := testRepoCreate()
+	require.NoError(t, err)
+	repo, err = testRepoWithTags(repo, []string{"v1.0.0"})
+	require.NoError(t, err)
+
+	// A rebased commit keeps its author time but gets a new committer time
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+	addFile(t, workTree, "rebased.txt", "rebased")
+	_, err = workTree.Commit("Rebased", &git.CommitOptions{
+		Author:    &object.Signature{Name: "Test User", Email: "test@localhost", When: time.Unix(1600000000, 0)},
+		Committer: &object.Signature{Name: "Test User", Email: "test@localhost", When: time.Unix(1700000000, 0)},
+	})
+	require.NoError(t, err)
+
+	getVersion := func(opts LanguageVersionsOptions) string {
+		opts.Repo = repo
+		opts.Commitish = plumbing.Revision("HEAD")
+		opts.OmitCommitHash = true
+		version, err := GetVersionDetailsWithOptions(opts)
+		require.NoError(t, err)
+		return version.SemVer
+	}
+
+	require.Equal(t, "1.1.0-alpha.1700000000", getVersion(LanguageVersionsOptions{}))
+	require.Equal(t, "1.1.0-alpha.1600000000", getVersion(LanguageVersionsOptions{
+		TimestampSource: TimestampSourceAuthor,
+	}))
+	require.Equal(t, "1.1.0-alpha.1500000000", getVersion(LanguageVersionsOptions{
+		Timestamp:       time.Unix(1500000000, 0),
+		TimestampSource: TimestampSourceAuthor,
+	}))
+}
@@ -0,0 +1,283 @@
+// Package manifest reads and rewrites the version recorded in package manifests, such as
+// package.json or pom.xml, so that it can be kept in step with the version calculated from git.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+)
+
+// Kind identifies the format of a manifest, and so which language-specific version it holds.
+type Kind string
+
+const (
+	KindPackageJSON Kind = "package.json"
+	KindPyProject   Kind = "pyproject.toml"
+	KindSetupPy     Kind = "setup.py"
+	KindCSProj      Kind = "csproj"
+	KindPom         Kind = "pom.xml"
+	KindVersionGo   Kind = "version.go"
+)
+
+// KindForPath returns the kind of the manifest at `path` from its file name.
+func KindForPath(path string) (Kind, error) {
+	base := filepath.Base(path)
+	switch {
+	case base == "package.json":
+		return KindPackageJSON, nil
+	case base == "pyproject.toml":
+		return KindPyProject, nil
+	case base == "setup.py":
+		return KindSetupPy, nil
+	case strings.HasSuffix(base, ".csproj"):
+		return KindCSProj, nil
+	case base == "pom.xml":
+		return KindPom, nil
+	case base == "version.go":
+		return KindVersionGo, nil
+	default:
+		return "", fmt.Errorf("unsupported manifest %q, expected package.json, pyproject.toml, setup.py, "+
+			"*.csproj, pom.xml or version.go", path)
+	}
+}
+
+// Version returns the form of `versions` written to manifests of this kind.
+func (k Kind) Version(versions *gitversion.LanguageVersions) string {
+	switch k {
+	case KindPackageJSON:
+		// npm versions don't take the "v" prefix used for tags
+		return strings.TrimPrefix(versions.JavaScript, "v")
+	case KindPyProject, KindSetupPy:
+		return versions.Python
+	case KindCSProj:
+		return versions.DotNet
+	case KindPom:
+		return versions.Java
+	default:
+		return versions.SemVer
+	}
+}
+
+// Manifest is a manifest file along with the location of the version within it.
+type Manifest struct {
+	Path string
+	Kind Kind
+	// Current is the version currently in the manifest.
+	Current string
+
+	content    []byte
+	start, end int
+}
+
+// Read loads the manifest at `path` and finds its version.
+func Read(path string) (*Manifest, error) {
+	kind, err := KindForPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end int
+	switch kind {
+	case KindPackageJSON:
+		start, end, err = findJSONVersion(content)
+	case KindPyProject:
+		start, end, err = findPyProjectVersion(content)
+	case KindSetupPy:
+		start, end, err = findRegexpVersion(content, setupPyVersionRe)
+	case KindCSProj:
+		start, end, err = findRegexpVersion(content, csprojVersionRe)
+	case KindPom:
+		start, end, err = findPomVersion(content)
+	case KindVersionGo:
+		start, end, err = findRegexpVersion(content, goVersionRe)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading version from %q: %w", path, err)
+	}
+
+	return &Manifest{
+		Path:    path,
+		Kind:    kind,
+		Current: string(content[start:end]),
+		content: content,
+		start:   start,
+		end:     end,
+	}, nil
+}
+
+// Want returns the version the manifest should hold for `versions`. A leading "v" on the current
+// version is kept, as some version.go files record the tag rather than the version.
+func (m *Manifest) Want(versions *gitversion.LanguageVersions) string {
+	want := m.Kind.Version(versions)
+	if strings.HasPrefix(m.Current, "v") && !strings.HasPrefix(want, "v") {
+		want = "v" + want
+	}
+	return want
+}
+
+// Write replaces the version in the manifest with `version` and saves it, leaving the rest of the
+// file untouched.
+func (m *Manifest) Write(version string) error {
+	var updated bytes.Buffer
+	updated.Write(m.content[:m.start])
+	updated.WriteString(version)
+	updated.Write(m.content[m.end:])
+
+	stat, err := os.Stat(m.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(m.Path, updated.Bytes(), stat.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing %q: %w", m.Path, err)
+	}
+
+	m.content = updated.Bytes()
+	m.end = m.start + len(version)
+	m.Current = version
+	return nil
+}
+
+var (
+	setupPyVersionRe = regexp.MustCompile(`\bversion\s*=\s*["']([^"'\n]*)["']`)
+	csprojVersionRe  = regexp.MustCompile(`<Version>\s*([^<\s]*)\s*</Version>`)
+	goVersionRe      = regexp.MustCompile(`(?m)^\s*(?:(?:const|var)\s+)?Version\s*(?:string\s*)?=\s*"([^"\n]*)"`)
+
+	tomlTableRe   = regexp.MustCompile(`^\s*\[\s*([^\]]+?)\s*\]`)
+	tomlVersionRe = regexp.MustCompile(`^\s*version\s*=\s*["']([^"'\n]*)["']`)
+)
+
+// findRegexpVersion returns the location of the first submatch of `re` in `content`.
+func findRegexpVersion(content []byte, re *regexp.Regexp) (int, int, error) {
+	loc := re.FindSubmatchIndex(content)
+	if loc == nil {
+		return 0, 0, errors.New("no version found")
+	}
+	return loc[2], loc[3], nil
+}
+
+// findJSONVersion returns the location of the top-level "version" string in a JSON document,
+// skipping any nested "version" keys such as those in "pulumi" plugin metadata.
+func findJSONVersion(content []byte) (int, int, error) {
+	type container struct {
+		object bool
+		// key is set when the next token in an object is a key rather than a value
+		key bool
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	var stack []*container
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return 0, 0, errors.New("no top-level version found")
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+		} else if len(stack) > 0 && stack[len(stack)-1].key {
+			stack[len(stack)-1].key = false
+			if len(stack) == 1 && token == "version" {
+				value, err := decoder.Token()
+				if err != nil {
+					return 0, 0, err
+				}
+				if _, ok := value.(string); !ok {
+					return 0, 0, errors.New("top-level version is not a string")
+				}
+				end := int(decoder.InputOffset()) - 1
+				start := bytes.LastIndexByte(content[:end], '"') + 1
+				return start, end, nil
+			}
+			continue
+		} else if delim, ok := token.(json.Delim); ok {
+			stack = append(stack, &container{object: delim == '{', key: delim == '{'})
+			continue
+		}
+
+		// A value was completed, so an enclosing object expects another key
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].key = true
+		}
+	}
+}
+
+// findPyProjectVersion returns the location of the version in the [project] table of a
+// pyproject.toml file, falling back to the [tool.poetry] table.
+func findPyProjectVersion(content []byte) (int, int, error) {
+	found := map[string][2]int{}
+	table := ""
+	offset := 0
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if match := tomlTableRe.FindSubmatch(line); match != nil {
+			table = string(match[1])
+		} else if loc := tomlVersionRe.FindSubmatchIndex(line); loc != nil {
+			if _, ok := found[table]; !ok {
+				found[table] = [2]int{offset + loc[2], offset + loc[3]}
+			}
+		}
+		offset += len(line)
+	}
+
+	for _, table := range []string{"project", "tool.poetry"} {
+		if loc, ok := found[table]; ok {
+			return loc[0], loc[1], nil
+		}
+	}
+	return 0, 0, errors.New("no version found in the [project] or [tool.poetry] tables")
+}
+
+// findPomVersion returns the location of the version of the project itself in a pom.xml file, as
+// opposed to the versions of its parent, dependencies or plugins.
+func findPomVersion(content []byte) (int, int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var stack []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return 0, 0, errors.New("no project version found")
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, token.Name.Local)
+			if len(stack) != 2 || stack[0] != "project" || token.Name.Local != "version" {
+				continue
+			}
+			start := int(decoder.InputOffset())
+			if _, err := decoder.Token(); err != nil {
+				return 0, 0, err
+			}
+			end := int(decoder.InputOffset())
+			// The element may be empty, in which case the offset is past the end tag
+			if bytes.HasPrefix(content[start:], []byte("</")) {
+				end = start
+			}
+			value := content[start:end]
+			trimmedStart := start + len(value) - len(bytes.TrimLeft(value, " \t\r\n"))
+			return trimmedStart, start + len(bytes.TrimRight(value, " \t\r\n")), nil
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
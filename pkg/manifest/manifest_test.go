@@ -0,0 +1,170 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulumi/pulumictl/pkg/gitversion"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifests(t *testing.T) {
+	versions, err := gitversion.GetLanguageOptionsFromVersion("v3.2.0-alpha.1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		current  string
+		expected string
+	}{
+		{
+			name: "package.json",
+			file: "package.json",
+			content: `{
+  "name": "@pulumi/aws",
+  "pulumi": {"resource": true, "version": "${VERSION}"},
+  "dependencies": [{"version": "1.0.0"}],
+  "version": "${VERSION}",
+  "license": "Apache-2.0"
+}
+`,
+			current: "${VERSION}",
+			expected: `{
+  "name": "@pulumi/aws",
+  "pulumi": {"resource": true, "version": "${VERSION}"},
+  "dependencies": [{"version": "1.0.0"}],
+  "version": "3.2.0-alpha.1",
+  "license": "Apache-2.0"
+}
+`,
+		},
+		{
+			name: "pyproject.toml",
+			file: "pyproject.toml",
+			content: `[build-system]
+version = "1"
+
+[project]
+name = "pulumi_aws"
+version = "0.0.0"
+`,
+			current: "0.0.0",
+			expected: `[build-system]
+version = "1"
+
+[project]
+name = "pulumi_aws"
+version = "3.2.0a1"
+`,
+		},
+		{
+			name: "pyproject.toml with poetry",
+			file: "pyproject.toml",
+			content: `[tool.poetry]
+version = '1.0.0'
+`,
+			current: "1.0.0",
+			expected: `[tool.poetry]
+version = '3.2.0a1'
+`,
+		},
+		{
+			name:     "setup.py",
+			file:     "setup.py",
+			content:  "setup(name='pulumi_aws',\n      version=VERSION,\n      python_requires='>=3.8')\n",
+			current:  "",
+			expected: "",
+		},
+		{
+			name:     "setup.py with literal version",
+			file:     "setup.py",
+			content:  "setup(name='pulumi_aws',\n      version='0.0.0',\n      python_requires='>=3.8')\n",
+			current:  "0.0.0",
+			expected: "setup(name='pulumi_aws',\n      version='3.2.0a1',\n      python_requires='>=3.8')\n",
+		},
+		{
+			name:     "csproj",
+			file:     "Pulumi.Aws.csproj",
+			content:  "<Project>\n  <PropertyGroup>\n    <Version>0.0.1</Version>\n  </PropertyGroup>\n</Project>\n",
+			current:  "0.0.1",
+			expected: "<Project>\n  <PropertyGroup>\n    <Version>3.2.0-alpha.1</Version>\n  </PropertyGroup>\n</Project>\n",
+		},
+		{
+			name: "pom.xml",
+			file: "pom.xml",
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent><version>1.0.0</version></parent>
+  <dependencies><dependency><version>2.0.0</version></dependency></dependencies>
+  <version> 0.0.1 </version>
+</project>
+`,
+			current: "0.0.1",
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <parent><version>1.0.0</version></parent>
+  <dependencies><dependency><version>2.0.0</version></dependency></dependencies>
+  <version> 3.2.0-alpha.1 </version>
+</project>
+`,
+		},
+		{
+			name:     "empty pom.xml version",
+			file:     "pom.xml",
+			content:  "<project><version></version></project>",
+			current:  "",
+			expected: "<project><version>3.2.0-alpha.1</version></project>",
+		},
+		{
+			name:     "version.go",
+			file:     "version.go",
+			content:  "package version\n\n// Version is set at build time.\nvar Version string = \"0.0.1\"\n",
+			current:  "0.0.1",
+			expected: "package version\n\n// Version is set at build time.\nvar Version string = \"3.2.0-alpha.1\"\n",
+		},
+		{
+			name:     "version.go with tag",
+			file:     "version.go",
+			content:  "package version\n\nconst (\n\tVersion = \"v0.0.1\"\n)\n",
+			current:  "v0.0.1",
+			expected: "package version\n\nconst (\n\tVersion = \"v3.2.0-alpha.1\"\n)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			m, err := Read(path)
+			if tt.expected == "" {
+				require.ErrorContains(t, err, "no version found")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.current, m.Current)
+
+			require.NoError(t, m.Write(m.Want(versions)))
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(content))
+
+			m, err = Read(path)
+			require.NoError(t, err)
+			require.Equal(t, m.Want(versions), m.Current)
+		})
+	}
+}
+
+func TestKindForPath(t *testing.T) {
+	kind, err := KindForPath("sdk/dotnet/Pulumi.Aws.csproj")
+	require.NoError(t, err)
+	require.Equal(t, KindCSProj, kind)
+
+	_, err = KindForPath("sdk/go.mod")
+	require.ErrorContains(t, err, "unsupported manifest")
+}